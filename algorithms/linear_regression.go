@@ -5,7 +5,6 @@ import (
   "log"
   "math"
   "math/rand"
-  "github.com/rom1mouret/ml-essentials/utils"
   "github.com/rom1mouret/ml-essentials/dataframe"
   "github.com/rom1mouret/ml-essentials/preprocessing"
   "gonum.org/v1/gonum/floats"
@@ -41,6 +40,17 @@ type LinearRegressor struct {
   TargetScaler     *preprocessing.Scaler
   Weights          []float64
   Features         []string
+  Schema           *dataframe.Schema
+}
+
+// the features of the prediction data must match the training features, but
+// their type can differ, e.g. bools given as ints.
+var linRegConformOptions = dataframe.ConformOptions{
+  MissingColumns: dataframe.RejectDiscrepancy,
+  ExtraColumns: dataframe.IgnoreDiscrepancy,
+  TypeMismatches: dataframe.FixDiscrepancy,
+  OutOfRange: dataframe.IgnoreDiscrepancy,
+  UnknownCategories: dataframe.IgnoreDiscrepancy,
 }
 
 func NewLinearRegressor() *LinearRegressor {
@@ -73,6 +83,7 @@ func (reg *LinearRegressor) Fit(df *dataframe.DataFrame, targetColumn string,
   }
   // training features and their corresponding weights
   reg.Features = floatsAndBools.Except(targetColumn).NameList()
+  reg.Schema = dataframe.CaptureSchema(df.ColumnView(reg.Features...), dataframe.SchemaOptions{})
   reg.Weights = make([]float64, len(reg.Features))
   for i := range reg.Weights {
     reg.Weights[i] = rand.NormFloat64()
@@ -87,49 +98,54 @@ func (reg *LinearRegressor) Fit(df *dataframe.DataFrame, targetColumn string,
   if params.Momentum > 0 {
     prevGradients = mat.NewVecDense(len(reg.Weights), nil)
   }
-  // pre-allocation
-  diff := make([]float64, params.BatchSize)
+  // allocated with the first batch, since the loader may fall back to its
+  // default batch size
+  var diff []float64
 
   // training loop
-  batching := dataframe.NewDense64Batching(reg.Features)
-  for _, epoch := range utils.MakeRange(1, params.Epochs+1, 1) {
-    start := time.Now()
-    var batches []*dataframe.DataFrame
-    if params.LowMemory {
-      batches = df.ShuffleView().SplitView(params.BatchSize)
-    } else {
-      // Copy() sometimes makes DenseMatrix() faster
-      batches = df.ShuffleView().Copy().SplitView(params.BatchSize)
-    }
-    absErr := 0.0
-    for _, batch := range batches {
-      batchSize := batch.NumRows()
-      diffVec := mat.NewVecDense(batchSize, diff[:batchSize])
-      y := batch.Floats("_target").VecDense()
-      rows := batching.DenseMatrix(batch)
-      // diff = rows x weights - y  [dim: batch size]
-      diffVec.MulVec(rows, weights)
-      diffVec.SubVec(diffVec, y)
+  absErr := 0.0
+  start = time.Now()
+  loader := dataframe.NewBatchLoader(df, reg.Features, "_target", dataframe.BatchLoaderOptions{
+    BatchSize: params.BatchSize,
+    Epochs: params.Epochs,
+    Shuffle: true,
+    Compact: !params.LowMemory,  // Copy() sometimes makes DenseMatrix() faster
+    OnEpochEnd: func(epoch int) {
       if params.Verbose {
-        absErr += blas64.Asum(diffVec.RawVector())
-      }
-      // gradients = SUM(MulElem(batch, diff), axis=0), i.e. transpose(batch) * diff
-      gradients.MulVec(rows.T(), diffVec)
-      if params.Momentum > 0 {
-        gradients.AddScaledVec(gradients, params.Momentum, prevGradients)
-        prevGradients.CopyVec(gradients)
-      }
-      // weight update
-      weights.AddScaledVec(weights, -params.LR, gradients)
-      // weight decay
-      if params.WeightDecay > 0 {
-        weights.ScaleVec(1-params.WeightDecay, weights)
+        mae := absErr / float64(df.NumRows())
+        log.Printf("training epoch %d took: %s (scaled MAE: %f)",
+                   epoch+1, time.Since(start), mae)
       }
+      absErr = 0
+      start = time.Now()
+    },
+  })
+  defer loader.Close()
+  for batch := loader.Next(); batch != nil; batch = loader.Next() {
+    batchSize := batch.Rows.NumRows()
+    if len(diff) < batchSize {
+      diff = make([]float64, batchSize)
     }
+    diffVec := mat.NewVecDense(batchSize, diff[:batchSize])
+    y := batch.Target
+    rows := batch.Features
+    // diff = rows x weights - y  [dim: batch size]
+    diffVec.MulVec(rows, weights)
+    diffVec.SubVec(diffVec, y)
     if params.Verbose {
-      mae := absErr / float64(df.NumRows())
-      log.Printf("training epoch %d took: %s (scaled MAE: %f)",
-                 epoch, time.Since(start), mae)
+      absErr += blas64.Asum(diffVec.RawVector())
+    }
+    // gradients = SUM(MulElem(batch, diff), axis=0), i.e. transpose(batch) * diff
+    gradients.MulVec(rows.T(), diffVec)
+    if params.Momentum > 0 {
+      gradients.AddScaledVec(gradients, params.Momentum, prevGradients)
+      prevGradients.CopyVec(gradients)
+    }
+    // weight update
+    weights.AddScaledVec(weights, -params.LR, gradients)
+    // weight decay
+    if params.WeightDecay > 0 {
+      weights.ScaleVec(1-params.WeightDecay, weights)
     }
   }
   return nil
@@ -139,11 +155,20 @@ func (reg *LinearRegressor) Fit(df *dataframe.DataFrame, targetColumn string,
 // float column identified by "resultColumn" argument.
 // The new dataframe is a view on the original dataframe, so they share most
 // data.
-// As of now, it always returns nil alongside the result dataframe, but it could
-// return an error in future versions.
+// Features whose type differs from the training data are converted if the
+// conversion is lossless, e.g. an int column given instead of a float column.
+// It returns a *dataframe.ConformError if a feature is missing or cannot be
+// converted.
 // If resultColumn already exists, the previous data will be overwritten but it
 // will not change the data in the original dataframe.
 func (reg *LinearRegressor) Predict(df *dataframe.DataFrame, resultColumn string) (*dataframe.DataFrame, error) {
+  if reg.Schema != nil {
+    conformed, err := reg.Schema.Conform(df, linRegConformOptions)
+    if err != nil {
+      return nil, err
+    }
+    df = conformed
+  }
   df = df.ResetIndexView() // makes batching.DenseMatrix faster
 
   // simple heuristic to maximize the batch size without blowing up the memory
@@ -51,4 +51,22 @@ func Test1LinearRegression(t *testing.T) {
   mae := MAE(df.Floats("target"), df.Floats("y_pred"))
   fmt.Println("mae", mae)
   utils.AssertTrue("mae", mae < 0.16, t)
+  // missing features
+  _, err = model.Predict(df.ColumnView("target"), "y_pred")
+  utils.AssertTrue("missing features", err != nil, t)
+}
+
+func TestLinearRegressionDefaultBatchSize(t *testing.T) {
+  b := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  for i := 0; i < 100; i++ {
+    x := rand.NormFloat64()
+    b.AddFloats("x", x)
+    b.AddFloats("target", 2 * x)
+  }
+  df := b.ToDataFrame()
+  model := NewLinearRegressor()
+  for _, batchSize := range []int{0, -1} {
+    err := model.Fit(df, "target", LinRegTrainParams{Epochs: 2, LR: 0.001, BatchSize: batchSize})
+    utils.AssertNoError(err, t)
+  }
 }
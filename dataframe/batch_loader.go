@@ -0,0 +1,251 @@
+package dataframe
+
+import (
+  "sync"
+  "gonum.org/v1/gonum/mat"
+)
+
+// BatchLoaderOptions specifies how BatchLoader iterates over the dataframe.
+type BatchLoaderOptions struct {
+  // Number of rows per batch. Default: 32
+  BatchSize int
+  // Number of times the dataframe is iterated over. Default: 1
+  Epochs    int
+  // Whether the dataframe is shuffled at the beginning of each epoch.
+  Shuffle   bool
+  // Whether the last batch of each epoch is dropped if it is smaller than
+  // BatchSize.
+  DropLast  bool
+  // Whether the shuffled dataframe is copied before being split into batches.
+  // Copies speed up the assembly of the matrices at the cost of memory.
+  Compact   bool
+  // Number of batches assembled in advance. Default: 2
+  Prefetch  int
+  // Number of goroutines assembling the batches.
+  // Zero and negative values mean one goroutine.
+  Workers   int
+  // Function called at the end of each epoch, with epochs numbered from 0.
+  // It is called from the goroutine that calls Next, before Next returns the
+  // first batch of the following epoch.
+  OnEpochEnd func(epoch int)
+}
+
+// Batch is a minibatch delivered by BatchLoader.
+// Its matrices are recycled by the BatchLoader, so they are only valid until
+// the next call to BatchLoader.Next.
+type Batch struct {
+  // Epoch number, starting from 0.
+  Epoch    int
+  // Position of the batch within its epoch, starting from 0.
+  Index    int
+  // Rows of the batch, as a view on the original dataframe.
+  Rows     *DataFrame
+  // Feature matrix, with one row per row of the batch and columns ordered like
+  // the feature columns given to NewBatchLoader.
+  Features mat.Matrix
+  // Target vector. It is nil if no target column was given.
+  Target   *mat.VecDense
+  buffer   *batchBuffer
+}
+
+// BatchLoader assembles gonum matrices from a dataframe in background
+// goroutines, so that training loops don't have to wait on the data.
+// Intended use:
+//  loader := NewBatchLoader(df, features, "target", opt)
+//  defer loader.Close()
+//  for batch := loader.Next(); batch != nil; batch = loader.Next() {
+//    // use batch.Features and batch.Target
+//  }
+type BatchLoader struct {
+  df       *DataFrame
+  features []string
+  target   string
+  options  BatchLoaderOptions
+  ready    chan chan *Batch
+  jobs     chan batchJob
+  pool     chan *batchBuffer
+  done     chan struct{}
+  closing  sync.Once
+  current  *Batch
+}
+
+type batchJob struct {
+  epoch int
+  index int
+  rows  *DataFrame
+  slot  chan *Batch
+}
+
+type batchBuffer struct {
+  batching *Dense64Batching
+  target   []float64
+}
+
+// NewBatchLoader creates a BatchLoader and starts assembling batches right
+// away in background goroutines.
+// features are the float, int and bool columns of the feature matrix.
+// target is the name of a float, int or bool column, or an empty string if no
+// target vector is needed.
+// It will panic if a column doesn't exist or is an object column.
+// Call Close if you stop iterating before the end of the last epoch, otherwise
+// the background goroutines will leak.
+func NewBatchLoader(df *DataFrame, features []string, target string, options BatchLoaderOptions) *BatchLoader {
+  if options.BatchSize <= 0 {
+    options.BatchSize = 32
+  }
+  if options.Epochs <= 0 {
+    options.Epochs = 1
+  }
+  if options.Prefetch <= 0 {
+    options.Prefetch = 2
+  }
+  if options.Workers <= 0 {
+    options.Workers = 1
+  }
+  // check the columns early on in the calling goroutine
+  var check FloatBatching
+  columns := features
+  if len(target) > 0 {
+    columns = append(append([]string{}, features...), target)
+  }
+  check.initialize(df, columns)
+
+  loader := &BatchLoader{
+    df: df,
+    features: features,
+    target: target,
+    options: options,
+    ready: make(chan chan *Batch, options.Prefetch),
+    jobs: make(chan batchJob, options.Prefetch),
+    done: make(chan struct{}),
+  }
+  // Prefetch+2 buffers is enough for every batch waiting in the ready channel,
+  // plus the batch awaited by Next and the batch being used by the caller.
+  loader.pool = make(chan *batchBuffer, options.Prefetch + 2)
+  for i := 0; i < cap(loader.pool); i++ {
+    loader.pool <- &batchBuffer{batching: NewDense64Batching(features)}
+  }
+  go loader.produce()
+  for i := 0; i < options.Workers; i++ {
+    go loader.work()
+  }
+  return loader
+}
+
+// produce splits the dataframe into batches and schedules them in order.
+func (loader *BatchLoader) produce() {
+  defer close(loader.jobs)
+  defer close(loader.ready)
+  opt := loader.options
+  for epoch := 0; epoch < opt.Epochs; epoch++ {
+    df := loader.df
+    if opt.Shuffle {
+      df = df.ShuffleView()
+    }
+    if opt.Compact {
+      df = df.Copy()
+    }
+    if df.NumRows() == 0 {
+      continue
+    }
+    batches := df.SplitView(opt.BatchSize)
+    if opt.DropLast && batches[len(batches)-1].NumRows() < opt.BatchSize {
+      batches = batches[:len(batches)-1]
+    }
+    for k, rows := range batches {
+      // the slot is queued before the job so that Next gets the batches in order
+      slot := make(chan *Batch, 1)
+      select {
+      case loader.ready <- slot:
+      case <-loader.done:
+        return
+      }
+      select {
+      case loader.jobs <- batchJob{epoch: epoch, index: k, rows: rows, slot: slot}:
+      case <-loader.done:
+        return
+      }
+    }
+  }
+}
+
+// work assembles the matrices of the scheduled batches.
+func (loader *BatchLoader) work() {
+  for job := range loader.jobs {
+    var buffer *batchBuffer
+    select {
+    case buffer = <-loader.pool:
+    case <-loader.done:
+      return
+    }
+    batch := &Batch{Epoch: job.epoch, Index: job.index, Rows: job.rows, buffer: buffer}
+    batch.Features = buffer.batching.DenseMatrix(job.rows)
+    if len(loader.target) > 0 {
+      batch.Target = buffer.fillTarget(job.rows, loader.target)
+    }
+    job.slot <- batch
+  }
+}
+
+func (buffer *batchBuffer) fillTarget(df *DataFrame, target string) *mat.VecDense {
+  nRows := df.NumRows()
+  if len(buffer.target) < nRows {
+    buffer.target = make([]float64, nRows)
+  }
+  data := buffer.target[:nRows]
+  if vals, ok := df.floats[target]; ok {
+    for j, i := range df.indices {
+      data[j] = vals[i]
+    }
+  } else if vals, ok := df.ints[target]; ok {
+    for j, i := range df.indices {
+      data[j] = float64(vals[i])
+    }
+  } else {
+    vals := df.bools[target]
+    for j, i := range df.indices {
+      if vals[i] {
+        data[j] = 1
+      } else {
+        data[j] = 0
+      }
+    }
+  }
+  return mat.NewVecDense(nRows, data)
+}
+
+// Next returns the next batch, or nil if all the epochs have been iterated
+// over or if the loader was closed.
+// The previous batch returned by Next is recycled, so its matrices must not be
+// used anymore.
+// Next is not safe for concurrent use.
+func (loader *BatchLoader) Next() *Batch {
+  previous := loader.current
+  loader.current = nil
+  if previous != nil {
+    // the pool has enough room for all the buffers
+    loader.pool <- previous.buffer
+  }
+  var batch *Batch
+  if slot, more := <-loader.ready; more {
+    select {
+    case batch = <-slot:
+    case <-loader.done:
+      return nil
+    }
+  }
+  endOfEpoch := previous != nil && (batch == nil || batch.Epoch != previous.Epoch)
+  if endOfEpoch && loader.options.OnEpochEnd != nil {
+    loader.options.OnEpochEnd(previous.Epoch)
+  }
+  loader.current = batch
+  return batch
+}
+
+// Close stops the background goroutines.
+// It is safe to call Close multiple times, and after the last batch.
+func (loader *BatchLoader) Close() {
+  loader.closing.Do(func() {
+    close(loader.done)
+  })
+}
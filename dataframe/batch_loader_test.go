@@ -0,0 +1,56 @@
+package dataframe
+
+import (
+    "testing"
+    u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func TestBatchLoader(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  n := 103
+  ints := u.MakeRange(0, n, 1)
+  floats := make([]float64, n)
+  for i, v := range ints {
+    floats[i] = float64(2 * v)
+  }
+  builder.AddInts("x", ints...).AddFloats("y", floats...)
+  df := builder.ToDataFrame()
+
+  for _, dropLast := range []bool{false, true} {
+    epochs := make([]int, 0)
+    opt := BatchLoaderOptions{
+      BatchSize: 10, Epochs: 3, Shuffle: true, DropLast: dropLast, Workers: 3,
+      OnEpochEnd: func(epoch int) { epochs = append(epochs, epoch) },
+    }
+    loader := NewBatchLoader(df, []string{"x"}, "y", opt)
+    rows := 0
+    for batch := loader.Next(); batch != nil; batch = loader.Next() {
+      r, c := batch.Features.Dims()
+      u.AssertIntEquals("num cols", c, 1, t)
+      u.AssertIntEquals("num rows", r, batch.Target.Len(), t)
+      for i := 0; i < r; i++ {
+        u.AssertFloatEquals("target", batch.Target.AtVec(i), 2 * batch.Features.At(i, 0), t)
+      }
+      rows += r
+    }
+    loader.Close()
+    u.AssertIntSliceEquals("epochs", epochs, []int{0, 1, 2}, t)
+    if dropLast {
+      u.AssertIntEquals("rows", rows, 300, t)
+    } else {
+      u.AssertIntEquals("rows", rows, 3 * n, t)
+    }
+  }
+}
+
+func TestBatchLoaderEarlyClose(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("x", make([]float64, 1000)...)
+  loader := NewBatchLoader(builder.ToDataFrame(), []string{"x"}, "", BatchLoaderOptions{Epochs: 5})
+  batch := loader.Next()
+  u.AssertTrue("no target", batch.Target == nil, t)
+  loader.Close()
+  loader.Close()
+  for batch := loader.Next(); batch != nil; batch = loader.Next() {
+  }
+}
@@ -2,6 +2,8 @@ package dataframe
 
 import (
   "fmt"
+  "sort"
+  "strings"
   "testing"
   "github.com/rom1mouret/ml-essentials/utils"
 )
@@ -87,3 +89,153 @@ func (data *RawData) CheckConsistency(t *testing.T) bool {
   }
   return true
 }
+
+// ViolationKind is the type of inconsistency reported by DataFrame.Validate().
+type ViolationKind uint8
+const(
+  // a column doesn't have as many rows as the other columns
+  LengthMismatch ViolationKind = iota
+  // an index of the view points outside the columns
+  IndexOutOfBounds
+  // a column of the string header is not an object column
+  NotAnObjectColumn
+  // a string column contains a value that is neither a string nor nil
+  NonStringValue
+  // a column name is used by columns of different types
+  DuplicateColumn
+)
+
+// Violation describes an inconsistency found by DataFrame.Validate().
+type Violation struct {
+  Kind    ViolationKind
+  // Empty if the violation is not related to a particular column.
+  Column  string
+  // Position in the view of the first offending row, or -1 if the violation
+  // is not related to a particular row.
+  Row     int
+  Message string
+}
+
+// ValidationError is the error returned by DataFrame.Validate().
+type ValidationError struct {
+  Violations []Violation
+}
+
+func (err *ValidationError) Error() string {
+  messages := make([]string, len(err.Violations))
+  for k, v := range err.Violations {
+    messages[k] = v.Message
+  }
+  return strings.Join(messages, "; ")
+}
+
+func (err *ValidationError) add(kind ViolationKind, col string, row int, format string, args ...interface{}) {
+  err.Violations = append(err.Violations, Violation{
+    Kind: kind,
+    Column: col,
+    Row: row,
+    Message: fmt.Sprintf(format, args...),
+  })
+}
+
+// Validate checks the internal consistency of the dataframe. Unlike
+// CheckConsistency, it doesn't require a testing context so it can be used
+// in production code.
+// It returns nil if the dataframe is consistent, otherwise a *ValidationError
+// that lists the violations. Out-of-bounds indices and non-string values are
+// reported once per column, alongside the first offending row.
+// The expected number of rows is the most common column length, ties being
+// broken in favor of the longest columns. Every column of a different length
+// is reported, in alphabetical order.
+// If the debug mode is on, view-returning functions call Validate on their
+// output and panic if it returns an error.
+func (df *DataFrame) Validate() error {
+  result := &ValidationError{}
+  type column struct {
+    name string
+    kind string
+    size int
+  }
+  columns := make([]column, 0, df.NumColumns())
+  for col, vals := range df.floats {
+    columns = append(columns, column{col, "float", len(vals)})
+  }
+  for col, vals := range df.ints {
+    columns = append(columns, column{col, "int", len(vals)})
+  }
+  for col, vals := range df.bools {
+    columns = append(columns, column{col, "bool", len(vals)})
+  }
+  for col, vals := range df.objects {
+    columns = append(columns, column{col, "object", len(vals)})
+  }
+  sort.Slice(columns, func(i, j int) bool {
+    a, b := columns[i], columns[j]
+    return a.name < b.name || (a.name == b.name && a.kind < b.kind)
+  })
+  // reference length
+  nRows := 0
+  counts := make(map[int]int)
+  for _, c := range columns {
+    counts[c.size]++
+    if counts[c.size] > counts[nRows] || (counts[c.size] == counts[nRows] && c.size > nRows) {
+      nRows = c.size
+    }
+  }
+  seen := make(map[string]string)
+  for _, c := range columns {
+    if other, ok := seen[c.name]; ok {
+      result.add(DuplicateColumn, c.name, -1, "column %s is both a %s and a %s column", c.name, other, c.kind)
+    }
+    seen[c.name] = c.kind
+    if c.size != nRows {
+      result.add(LengthMismatch, c.name, -1, "%s column %s has %d rows. Expected: %d", c.kind, c.name, c.size, nRows)
+    }
+  }
+  // indices (dataframes without columns don't have any row to point to)
+  outOfBounds := 0
+  for j, i := range df.indices {
+    if df.NumColumns() > 0 && (i < 0 || i >= nRows) {
+      if outOfBounds == 0 {
+        result.add(IndexOutOfBounds, "", j, "index %d at row %d is out of bounds [0, %d)", i, j, nRows)
+      }
+      outOfBounds++
+    }
+  }
+  if outOfBounds > 1 {
+    last := &result.Violations[len(result.Violations)-1]
+    last.Message += fmt.Sprintf(" (%d out-of-bounds indices)", outOfBounds)
+  }
+  // string columns
+  for _, col := range df.stringHeader.NameList() {
+    vals, ok := df.objects[col]
+    if !ok {
+      result.add(NotAnObjectColumn, col, -1, "string column %s is not an object column", col)
+      continue
+    }
+    for j, i := range df.indices {
+      if i < 0 || i >= len(vals) || vals[i] == nil {
+        continue
+      }
+      if _, isString := vals[i].(string); !isString {
+        result.add(NonStringValue, col, j, "string column %s holds a %T value at row %d", col, vals[i], j)
+        break
+      }
+    }
+  }
+  if len(result.Violations) == 0 {
+    return nil
+  }
+  return result
+}
+
+// debugValidate panics if the debug mode is on and the dataframe is not
+// consistent.
+func (df *DataFrame) debugValidate(context string) {
+  if !df.debug {
+    return
+  }
+  if err := df.Validate(); err != nil {
+    panic(fmt.Sprintf("%s returned an inconsistent dataframe: %s", context, err.Error()))
+  }
+}
@@ -0,0 +1,68 @@
+package dataframe
+
+import (
+    "testing"
+    u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func TestValidate(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("floats", 1, 2, 3)
+  builder.AddStrings("strings", "a", "b", "c")
+  df := builder.ToDataFrame()
+  u.AssertNoError(df.Validate(), t)
+  u.AssertNoError(df.ShuffleView().SliceView(1, 3).Validate(), t)
+
+  // corrupt a view
+  view := df.IndexView([]int{0, 1, 2}).View()
+  view.reallocateMaps()
+  view.floats["floats"] = []float64{1, 2}
+  view.objects["strings"] = []interface{}{"a", 2, "c"}
+  view.stringHeader.add("ints")
+  view.indices = []int{2, 1, 3, 4}
+  err, ok := view.Validate().(*ValidationError)
+  if !u.AssertTrue("validation error", ok, t) {
+    return
+  }
+  kinds := make(map[ViolationKind]Violation)
+  for _, v := range err.Violations {
+    kinds[v.Kind] = v
+  }
+  u.AssertIntEquals("num violations", len(err.Violations), 4, t)
+  u.AssertStringEquals("length", kinds[LengthMismatch].Column, "floats", t)
+  u.AssertIntEquals("out of bounds", kinds[IndexOutOfBounds].Row, 2, t)
+  u.AssertStringEquals("string header", kinds[NotAnObjectColumn].Column, "ints", t)
+  u.AssertIntEquals("non-string", kinds[NonStringValue].Row, 1, t)
+}
+
+func TestValidateLengthReference(t *testing.T) {
+  df := NewRawData().ToDataFrame()
+  df.ints["a"] = []int{1, 2}
+  df.floats["b"] = []float64{1, 2, 3}
+  df.bools["c"] = []bool{true, false, true}
+  df.indices = []int{0, 1}
+  // the majority length wins
+  for run := 0; run < 10; run++ {
+    err := df.Validate().(*ValidationError)
+    u.AssertIntEquals("num violations", len(err.Violations), 1, t)
+    u.AssertStringEquals("minority", err.Violations[0].Column, "a", t)
+  }
+  // ties are broken in favor of the longest columns
+  delete(df.bools, "c")
+  df.objects["c"] = []interface{}{1}
+  for run := 0; run < 10; run++ {
+    err := df.Validate().(*ValidationError)
+    u.AssertIntEquals("num mismatches", len(err.Violations), 2, t)
+    u.AssertStringEquals("first mismatch", err.Violations[0].Column, "a", t)
+    u.AssertStringEquals("second mismatch", err.Violations[1].Column, "c", t)
+  }
+}
+
+func TestDebugValidate(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("floats", 1, 2, 3)
+  df := builder.ToDataFrame().Debug(true)
+  df.ReverseView()
+  df.indices = []int{0, 5}
+  u.AssertTrue("panic", panics(func() { df.ReverseView() }), t)
+}
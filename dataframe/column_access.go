@@ -8,6 +8,7 @@ import (
 type ColumnAccess struct {
   indices     []int
   contiguous  bool
+  frozen      bool
 }
 
 // FloatAccess is a random-access iterator for float columns.
@@ -46,7 +47,8 @@ func (df *DataFrame) Ints(colName string) IntAccess {
   df.debugPrint("int column access")
   if data, ok := df.ints[colName]; ok {
     return IntAccess{
-        ColumnAccess: ColumnAccess{indices: df.indices},
+        ColumnAccess: ColumnAccess{indices: df.indices,
+                                   frozen: df.frozen.contains(colName)},
         rawData: data}
   } else {
     panic(fmt.Sprintf("%s is not in the list of int columns", colName))
@@ -58,7 +60,8 @@ func (df *DataFrame) Bools(colName string) BoolAccess {
   df.debugPrint("bool column access")
   if data, ok := df.bools[colName]; ok {
     return BoolAccess{
-        ColumnAccess: ColumnAccess{indices: df.indices},
+        ColumnAccess: ColumnAccess{indices: df.indices,
+                                   frozen: df.frozen.contains(colName)},
         rawData: data}
   } else {
     panic(fmt.Sprintf("%s is not in the list of bool columns", colName))
@@ -71,7 +74,8 @@ func (df *DataFrame) Floats(colName string) FloatAccess {
   if data, ok := df.floats[colName]; ok {
     return FloatAccess{
         ColumnAccess: ColumnAccess{indices: df.indices,
-                                   contiguous: !df.indexViewed},
+                                   contiguous: !df.indexViewed,
+                                   frozen: df.frozen.contains(colName)},
         rawData: data}
   } else {
     panic(fmt.Sprintf("%s is not in the list of float columns", colName))
@@ -84,7 +88,8 @@ func (df *DataFrame) Objects(colName string) ObjectAccess {
   df.debugPrint("object column access")
   if data, ok := df.objects[colName]; ok {
     return ObjectAccess{
-        ColumnAccess: ColumnAccess{indices: df.indices},
+        ColumnAccess: ColumnAccess{indices: df.indices,
+                                   frozen: df.frozen.contains(colName)},
         rawData: data}
   } else {
     panic(fmt.Sprintf("%s is not in the list of object columns", colName))
@@ -99,7 +104,8 @@ func (df *DataFrame) Strings(colName string) StringAccess {
       panic(fmt.Sprintf("%s is an object column but not marked as string", colName))
     }
     return StringAccess{
-        ColumnAccess: ColumnAccess{indices: df.indices},
+        ColumnAccess: ColumnAccess{indices: df.indices,
+                                   frozen: df.frozen.contains(colName)},
         rawData: data}
   } else {
     panic(fmt.Sprintf("%s is not in the list of object columns", colName))
@@ -130,18 +136,49 @@ func (access FloatAccess) VecDenseCopy() *mat.VecDense {
 }
 
 // VecDense creates a gonum's VecDense object from the dataframe's float data.
-// The data is not copied if the underlying dataframe is contiguous, otherwise
-// the data is copied.
+// The data is not copied if the underlying dataframe is contiguous and not
+// frozen, otherwise the data is copied.
 // Use this function if you are not going to change the returned VecDense and
 // want to avoid an unnecessary copy.
 func (access FloatAccess) VecDense() *mat.VecDense {
-  if access.contiguous {
+  if access.contiguous && !access.frozen {
     return mat.NewVecDense(len(access.rawData), access.rawData)
   }
   return access.VecDenseCopy()
 }
 
-// TODO: add VecDense for bools and ints
+// VecDenseCopy creates a gonum's VecDense object from the dataframe's int data.
+// It always copies and converts the data to float64.
+// Missing values are converted to -1.
+// If you need to convert the same column repeatedly, use a ConversionCache.
+func (access IntAccess) VecDenseCopy() *mat.VecDense {
+  data := make([]float64, len(access.indices))
+  for j, i := range access.indices {
+    data[j] = float64(access.rawData[i])
+  }
+  return mat.NewVecDense(len(data), data)
+}
+
+// VecDenseCopy creates a gonum's VecDense object from the dataframe's bool
+// data, with true converted to 1 and false converted to 0.
+// It always copies the data.
+// If you need to convert the same column repeatedly, use a ConversionCache.
+func (access BoolAccess) VecDenseCopy() *mat.VecDense {
+  data := make([]float64, len(access.indices))
+  for j, i := range access.indices {
+    if access.rawData[i] {
+      data[j] = 1
+    }
+  }
+  return mat.NewVecDense(len(data), data)
+}
+
+// checkWritable panics if the column is frozen.
+func (access ColumnAccess) checkWritable() {
+  if access.frozen {
+    panic("cannot write into a frozen column")
+  }
+}
 
 // Get returns the float value at the given index.
 func (access FloatAccess) Get(row int) float64 {
@@ -149,7 +186,9 @@ func (access FloatAccess) Get(row int) float64 {
 }
 
 // Set overwrites the float value at the given index.
+// It will panic if the column is frozen.
 func (access FloatAccess) Set(row int, val float64) {
+  access.checkWritable()
   access.rawData[access.indices[row]] = val
 }
 
@@ -160,6 +199,7 @@ func (access IntAccess) Get(row int) int {
 
 // Set overwrites the integer value at the given index.
 func (access IntAccess) Set(row int, val int) {
+  access.checkWritable()
   access.rawData[access.indices[row]] = val
 }
 
@@ -170,6 +210,7 @@ func (access BoolAccess) Get(row int) bool {
 
 // Set overwrites the boolean value at the given index.
 func (access BoolAccess) Set(row int, val bool) {
+  access.checkWritable()
   access.rawData[access.indices[row]] = val
 }
 
@@ -180,6 +221,7 @@ func (access ObjectAccess) Get(row int) interface{} {
 
 // Set overwrites the object at the given index.
 func (access ObjectAccess) Set(row int, val interface{}) {
+  access.checkWritable()
   access.rawData[access.indices[row]] = val
 }
 
@@ -190,5 +232,6 @@ func (access StringAccess) Get(row int) string {
 
 // Set overwrites the string at the given index.
 func (access StringAccess) Set(row int, val string) {
+  access.checkWritable()
   access.rawData[access.indices[row]] = val
 }
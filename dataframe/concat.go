@@ -147,7 +147,11 @@ func ColumnConcatView(dfs ...*DataFrame) (*DataFrame, error) {
       result.shared.add(col)
     }
     result.stringHeader.And(df.stringHeader)
+    if df.frozen.Num() > 0 {
+      result.freezeColumns(df.frozen.NameList()...)
+    }
   }
+  result.debugValidate("ColumnConcatView()")
   return result, nil
 }
 
@@ -1,6 +1,8 @@
 package dataframe
 
 import (
+  "bufio"
+  "compress/gzip"
   "runtime"
   "encoding/csv"
   "math"
@@ -241,6 +243,8 @@ func FromCSV(r io.Reader, options CSVReadingSpec) (*RawData, error) {
 // automatically inferred column types.
 // It returns any error returned by golang's builtin CSV reader.
 // It also returns an error if the file cannot be opened.
+// Gzip-compressed files are detected from their first bytes and decompressed
+// on the fly, regardless of their extension.
 // For the type inference, refer to FromCSV's documentation.
 func FromCSVFile(path string, options CSVReadingSpec) (*RawData, error) {
   f, err := os.Open(path)
@@ -248,7 +252,22 @@ func FromCSVFile(path string, options CSVReadingSpec) (*RawData, error) {
     return nil, err
   }
   defer f.Close()
-  return FromCSV(f, options)
+  r := bufio.NewReader(f)
+  if isGzip(r) {
+    gz, err := gzip.NewReader(r)
+    if err != nil {
+      return nil, err
+    }
+    defer gz.Close()
+    return FromCSV(gz, options)
+  }
+  return FromCSV(r, options)
+}
+
+// isGzip checks the magic number of gzip streams without consuming the reader.
+func isGzip(r *bufio.Reader) bool {
+  magic, err := r.Peek(2)
+  return err == nil && magic[0] == 0x1f && magic[1] == 0x8b
 }
 
 // FromCSVFilePattern searches for file paths that matches the given glob
@@ -257,6 +276,7 @@ func FromCSVFile(path string, options CSVReadingSpec) (*RawData, error) {
 // It returns any error returned by golang's builtin CSV reader.
 // It also returns an error if any of the matching file can't be opened.
 // If no file can be found, it returns (nil, nil).
+// Like FromCSVFile, it transparently decompresses gzip-compressed files.
 // For the type inference, refer to FromCSV's documentation.
 func FromCSVFilePattern(glob string, options CSVReadingSpec) (*RawData, error) {
   // list all the files we want to read
@@ -1,6 +1,7 @@
 package dataframe
 
 import (
+  "compress/gzip"
   "sync"
   "sort"
   "encoding/csv"
@@ -10,6 +11,15 @@ import (
   "os"
 )
 
+// CSVCompression is the compression algorithm of the files written by
+// ToCSVFiles and ToCSVDir.
+type CSVCompression int
+
+const(
+  NoCompression CSVCompression = iota
+  GzipCompression
+)
+
 type CSVWritingSpec struct {
   // missing values will be replaced with this string. Default: ""
   StringMissingMarker string
@@ -24,6 +34,9 @@ type CSVWritingSpec struct {
   // Options from https://golang.org/src/encoding/csv/writer.go
   Comma   rune // Field delimiter (set to ',' by NewWriter)
   UseCRLF bool // True to use \r\n as the line terminator
+  // Compression of the files written by ToCSVFiles and ToCSVDir.
+  // Each file is compressed in its own go routine. Default: NoCompression
+  Compression CSVCompression
 
   // TODO: BOM writing, maybe?
 }
@@ -144,25 +157,48 @@ func (df *DataFrame) ToCSVs(writers []io.Writer, options CSVWritingSpec) error {
 // ToCSVFiles writes the dataframe in CSV format in the given files.
 // The dataframe is split evenly between the files and each file is written
 // separately within their dedicated go routine.
+// If options.Compression is GzipCompression, the files are gzip-compressed,
+// whatever their extension.
 // It returns an error if one of the files doesn't allow writing.
 // It also forwards any error raised by golang's builtin CSV writer.
 func (df *DataFrame) ToCSVFiles(options CSVWritingSpec, paths ...string) error {
   writers := make([]io.Writer, len(paths))
+  files := make([]*os.File, 0, len(paths))
+  defer func() {
+    for _, f := range files {
+      f.Close()
+    }
+  }()
+  var compressors []*gzip.Writer
   for i, path := range paths {
     f, err := os.Create(path)
-    defer f.Close()
     if err != nil {
       return err
     }
+    files = append(files, f)
     writers[i] = f
+    if options.Compression == GzipCompression {
+      gz := gzip.NewWriter(f)
+      compressors = append(compressors, gz)
+      writers[i] = gz
+    }
+  }
+  err := df.ToCSVs(writers, options)
+  // flush the compressed data even if an error occurred
+  for _, gz := range compressors {
+    if closeErr := gz.Close(); err == nil {
+      err = closeErr
+    }
   }
-  return df.ToCSVs(writers, options)
+  return err
 }
 
 // ToCSVDir writes the dataframe in CSV format to files with the chosen prefix.
 // The prefix includes the directory.
 // Example of prefix: "/tmp/output/result"
 // This will write /tmp/output/result01.csv, /tmp/output/result02.csv etc.
+// or /tmp/output/result01.csv.gz etc. if options.Compression is
+// GzipCompression.
 // The dataframe is split evenly between the files and each file is written
 // separately within their dedicated go routine.
 // It returns an error if one of the files doesn't allow writing or if the
@@ -185,6 +221,9 @@ func (df *DataFrame) ToCSVDir(options CSVWritingSpec, prefix string) ([]string,
   } else if numFiles >= 10 {
     suffix = "%02d.csv"
   }
+  if options.Compression == GzipCompression {
+    suffix += ".gz"
+  }
   paths := make([]string, numFiles)
   for i := range paths {
     paths[i] = prefix + fmt.Sprintf(suffix, i)
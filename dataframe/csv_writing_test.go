@@ -45,3 +45,33 @@ func TestToMultiCSVFiles(t *testing.T) {
     }
   }
 }
+
+func TestGzipCSVDir(t *testing.T) {
+  dir, err := ioutil.TempDir("", "gzip")
+  if err != nil {
+    panic(err)
+  }
+  defer os.RemoveAll(dir)
+
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddInts("col", u.MakeRange(0, 1000, 1)...)
+  df := builder.ToDataFrame()
+  df.SetMaxCPU(2)
+  spec := CSVWritingSpec{MinRowsPerFile: 100, Compression: GzipCompression}
+  paths, err := df.ToCSVDir(spec, dir + "/part")
+  u.AssertNoError(err, t)
+  u.AssertIntEquals("num files", len(paths), df.ActualMaxCPU(), t)
+  u.AssertStringEquals("extension", paths[0][len(paths[0])-7:], ".csv.gz", t)
+
+  // compressed files are detected by their magic bytes
+  data, err := FromCSVFilePattern(dir + "/part*", CSVReadingSpec{MaxCPU: 2})
+  u.AssertNoError(err, t)
+  if data.CheckConsistency(t) {
+    u.AssertIntEquals("num rows", data.NumAllocatedRows(), 1000, t)
+    total := 0
+    for _, v := range data.ints["col"] {
+      total += v
+    }
+    u.AssertIntEquals("total", total, 999 * 500, t)
+  }
+}
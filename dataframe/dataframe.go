@@ -95,6 +95,8 @@ func (df *DataFrame) ThreadSafeMasking(enable bool) *DataFrame {
 // Debug enables or disable the debugging mode.
 // The debugging mode will print out some troubleshooting information via
 // golang's builtin logger.
+// It also makes view-returning functions panic if their output doesn't pass
+// Validate().
 // It returns the dataframe itself.
 func (df *DataFrame) Debug(enable bool) *DataFrame {
   df.debug = enable
@@ -4,6 +4,8 @@ package dataframe
 // of a dataframe.
 // Initialize the structure like that: DataFrameInternals{DF: your_df}
 // Normally not needed, hence the lack of documentation.
+// The functions returning column data will panic if the column is frozen,
+// since the returned slices could be used to alter the data.
 type DataFrameInternals struct {
   DF *DataFrame
 }
@@ -17,17 +19,21 @@ func (dfi DataFrameInternals) GetIndices() []int {
 }
 
 func (dfi DataFrameInternals) FloatData(columnName string) []float64 {
+  dfi.DF.checkWritable(columnName)
   return dfi.DF.floats[columnName]
 }
 
 func (dfi DataFrameInternals) IntData(columnName string) []int {
+  dfi.DF.checkWritable(columnName)
   return dfi.DF.ints[columnName]
 }
 
 func (dfi DataFrameInternals) BoolData(columnName string) []bool {
+  dfi.DF.checkWritable(columnName)
   return dfi.DF.bools[columnName]
 }
 
 func (dfi DataFrameInternals) ObjectData(columnName string) []interface{} {
+  dfi.DF.checkWritable(columnName)
   return dfi.DF.objects[columnName]
 }
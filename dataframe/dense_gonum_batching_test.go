@@ -0,0 +1,23 @@
+package dataframe
+
+import (
+    "testing"
+    u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func TestDense64BatchingGrowing(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("floats", 0, 1, 2, 3, 4)
+  builder.AddInts("ints", 5, 6, 7, 8, 9)
+  df := builder.ToDataFrame()
+  batching := NewDense64Batching([]string{"floats", "ints"})
+  // the second batch is larger than the first one
+  for _, n := range []int{2, 3, 5} {
+    m := batching.DenseMatrix(df.SliceView(0, n))
+    rows, cols := m.Dims()
+    u.AssertIntEquals("rows", rows, n, t)
+    u.AssertIntEquals("cols", cols, 2, t)
+    u.AssertFloatEquals("floats", m.At(n-1, 0), float64(n-1), t)
+    u.AssertFloatEquals("ints", m.At(n-1, 1), float64(n+4), t)
+  }
+}
@@ -0,0 +1,227 @@
+package dataframe
+
+import (
+  "fmt"
+  "sync"
+  "reflect"
+  "gonum.org/v1/gonum/mat"
+)
+
+// FromDense creates a RawData structure with one float column per column of
+// the given gonum matrix. names are the names of the new columns, in the same
+// order as the matrix's columns.
+// The data is copied, so the matrix can be safely altered afterwards.
+// It returns an error if the number of names doesn't match the number of
+// columns of the matrix.
+func FromDense(m mat.Matrix, names []string) (*RawData, error) {
+  nRows, nCols := m.Dims()
+  if nCols != len(names) {
+    return nil, fmt.Errorf("%d names given for a matrix of %d columns", len(names), nCols)
+  }
+  data := NewRawData()
+  for j, col := range names {
+    if _, exists := data.floats[col]; exists {
+      return nil, fmt.Errorf("column %s is given twice", col)
+    }
+    data.floats[col] = mat.Col(make([]float64, nRows), j, m)
+  }
+  return data, nil
+}
+
+// WriteDense writes the columns of the given gonum matrix into the float
+// columns identified by names, in the same order as the matrix's columns.
+// Row k of the matrix is written at row k of the dataframe, so the data is
+// written through the index view and shared with every dataframe that shares
+// the columns.
+// Columns that don't exist are created, like OverwriteFloats64 does.
+// This is typically used to store the output of a multi-output model:
+//  df.AllocFloats("output1", "output2")
+//  for _, batch := range df.SplitView(batchSize) {
+//    var pred mat.Dense
+//    pred.Mul(batching.DenseMatrix(batch), weights) // (features x outputs)
+//    batch.WriteDense(&pred, []string{"output1", "output2"})
+//  }
+// Here, the batches write their predictions into df's columns.
+// It returns an error if the dimensions don't match or if a column exists but
+// is not a float column.
+func (df *DataFrame) WriteDense(m mat.Matrix, names []string) error {
+  df.debugPrint("writing dense matrix on")
+  nRows, nCols := m.Dims()
+  if nCols != len(names) {
+    return fmt.Errorf("%d names given for a matrix of %d columns", len(names), nCols)
+  }
+  if nRows != df.NumRows() {
+    return fmt.Errorf("matrix has %d rows. Expected: %d", nRows, df.NumRows())
+  }
+  for _, col := range names {
+    if df.frozen.contains(col) {
+      return fmt.Errorf("column %s is frozen", col)
+    }
+    if _, ok := df.floats[col]; !ok && df.Header().NameSet()[col] {
+      return fmt.Errorf("column %s exists but is not a float column", col)
+    }
+  }
+  buffer := make([]float64, nRows)
+  for j, col := range names {
+    mat.Col(buffer, j, m)
+    df.OverwriteFloats64(col, buffer)
+  }
+  return nil
+}
+
+// ConversionCache keeps float64 conversions of int and bool columns around so
+// that they can be handed over to gonum multiple times without being converted
+// again.
+// Once a column is converted, VecDense is zero-copy on dataframes that are not
+// index-viewed, e.g. slices returned by SplitView on a compact dataframe.
+// Otherwise, the converted values are gathered from the cache, which is still
+// faster than converting them.
+// The cache identifies columns by their name and by the range of backing data
+// they cover, so it can be used across views and across dataframes. For
+// instance, the batches returned by SplitView are converted once and hit the
+// cache at every subsequent epoch, as long as the same batches are reused.
+// The cache holds on to the backing data of the converted columns until their
+// entries are evicted. It keeps at most MaxCachedConversions entries and
+// evicts the least recently used ones first.
+// However, the cache cannot detect in-place changes. Call Invalidate if you
+// alter an int or bool column after it has been cached.
+// ConversionCache is safe for concurrent use.
+type ConversionCache struct {
+  mutex   sync.Mutex
+  entries map[conversionKey]*conversionEntry
+  clock   uint64
+}
+
+// MaxCachedConversions is the maximum number of entries of a ConversionCache.
+const MaxCachedConversions = 256
+
+type conversionKey struct {
+  column string
+  source uintptr
+  size   int
+}
+
+// conversionEntry references the source slice so as to compare it on lookup
+// and to prevent the garbage collector from reusing its address.
+type conversionEntry struct {
+  ints      []int
+  bools     []bool
+  converted []float64
+  lastUsed  uint64
+}
+
+// NewConversionCache allocates a new empty ConversionCache.
+func NewConversionCache() *ConversionCache {
+  return &ConversionCache{entries: make(map[conversionKey]*conversionEntry)}
+}
+
+// Invalidate removes the given columns from the cache.
+// If no column is given, the entire cache is emptied.
+func (cache *ConversionCache) Invalidate(columns ...string) {
+  cache.mutex.Lock()
+  defer cache.mutex.Unlock()
+  if len(columns) == 0 {
+    cache.entries = make(map[conversionKey]*conversionEntry)
+    return
+  }
+  invalid := make(map[string]bool)
+  for _, col := range columns {
+    invalid[col] = true
+  }
+  for key := range cache.entries {
+    if invalid[key.column] {
+      delete(cache.entries, key)
+    }
+  }
+}
+
+// VecDense returns a gonum's VecDense object from the given int, bool or float
+// column, converted to float64.
+// Missing integers are converted to -1. Booleans are converted to 0 and 1.
+// Float columns are not cached since FloatAccess.VecDense is already zero-copy.
+// It will panic if the column is not an int, bool or float column.
+// The returned VecDense may share its data with the cache, so do not change it.
+func (cache *ConversionCache) VecDense(df *DataFrame, colName string) *mat.VecDense {
+  if _, ok := df.floats[colName]; ok {
+    return df.Floats(colName).VecDense()
+  }
+  converted := cache.converted(df, colName)
+  if !df.indexViewed {
+    data := converted[:len(df.indices)]
+    return mat.NewVecDense(len(data), data)
+  }
+  data := make([]float64, len(df.indices))
+  for j, i := range df.indices {
+    data[j] = converted[i]
+  }
+  return mat.NewVecDense(len(data), data)
+}
+
+// converted returns the float64 conversion of an entire backing column.
+func (cache *ConversionCache) converted(df *DataFrame, colName string) []float64 {
+  ints, isInt := df.ints[colName]
+  bools, isBool := df.bools[colName]
+  if !isInt && !isBool {
+    panic(fmt.Sprintf("%s is not an int, bool or float column", colName))
+  }
+  size := len(ints) + len(bools)
+  if size == 0 {
+    return nil
+  }
+  key := conversionKey{column: colName, size: size}
+  if isInt {
+    key.source = reflect.ValueOf(ints).Pointer()
+  } else {
+    key.source = reflect.ValueOf(bools).Pointer()
+  }
+  cache.mutex.Lock()
+  defer cache.mutex.Unlock()
+  cache.clock++
+  if entry, ok := cache.entries[key]; ok && entry.sameSource(ints, bools) {
+    entry.lastUsed = cache.clock
+    return entry.converted
+  }
+  converted := make([]float64, size)
+  if isInt {
+    for i, v := range ints {
+      converted[i] = float64(v)
+    }
+  } else {
+    for i, v := range bools {
+      if v {
+        converted[i] = 1
+      }
+    }
+  }
+  if len(cache.entries) >= MaxCachedConversions {
+    cache.evict()
+  }
+  cache.entries[key] = &conversionEntry{
+    ints: ints, bools: bools, converted: converted, lastUsed: cache.clock,
+  }
+
+  return converted
+}
+
+// sameSource returns true if the entry was converted from the given slice.
+func (entry *conversionEntry) sameSource(ints []int, bools []bool) bool {
+  if len(ints) > 0 {
+    return len(entry.ints) == len(ints) && cap(entry.ints) == cap(ints) &&
+           &entry.ints[0] == &ints[0]
+  }
+  return len(entry.bools) == len(bools) && cap(entry.bools) == cap(bools) &&
+         &entry.bools[0] == &bools[0]
+}
+
+// evict removes the least recently used entry.
+func (cache *ConversionCache) evict() {
+  var oldest conversionKey
+  first := true
+  for key, entry := range cache.entries {
+    if first || entry.lastUsed < cache.entries[oldest].lastUsed {
+      oldest = key
+      first = false
+    }
+  }
+  delete(cache.entries, oldest)
+}
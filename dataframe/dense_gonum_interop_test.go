@@ -0,0 +1,97 @@
+package dataframe
+
+import (
+    "testing"
+    "gonum.org/v1/gonum/mat"
+    u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func TestFromDense(t *testing.T) {
+  m := mat.NewDense(3, 2, []float64{1, 2, 3, 4, 5, 6})
+  data, err := FromDense(m, []string{"a", "b"})
+  if u.AssertNoError(err, t) && data.CheckConsistency(t) {
+    u.AssertFloatSliceEquals("a", data.floats["a"], []float64{1, 3, 5}, t)
+    u.AssertFloatSliceEquals("b", data.floats["b"], []float64{2, 4, 6}, t)
+  }
+  _, err = FromDense(m, []string{"a"})
+  u.AssertTrue("error expected", err != nil, t)
+}
+
+func TestWriteDense(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddInts("col", 0, 1, 2, 3)
+  df := builder.ToDataFrame()
+  df.AllocFloats("out1", "out2")
+  view := df.IndexView([]int{3, 1})
+  m := mat.NewDense(2, 2, []float64{1, 2, 3, 4})
+  if u.AssertNoError(view.WriteDense(m, []string{"out1", "out2"}), t) {
+    u.AssertFloatSliceEquals("out1", df.floats["out1"], []float64{0, 3, 0, 1}, t)
+    u.AssertFloatSliceEquals("out2", df.floats["out2"], []float64{0, 4, 0, 2}, t)
+  }
+  u.AssertTrue("not a float column", view.WriteDense(m, []string{"col", "out1"}) != nil, t)
+  u.AssertTrue("wrong size", df.WriteDense(m, []string{"out1", "out2"}) != nil, t)
+}
+
+func TestConversionCache(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddInts("ints", 0, 1, 2, 3)
+  builder.AddBools("bools", true, false, true, false)
+  df := builder.ToDataFrame()
+  cache := NewConversionCache()
+  v1 := cache.VecDense(df, "ints")
+  v2 := cache.VecDense(df, "ints")
+  u.AssertTrue("zero-copy", &v1.RawVector().Data[0] == &v2.RawVector().Data[0], t)
+  u.AssertFloatSliceEquals("ints", v1.RawVector().Data, []float64{0, 1, 2, 3}, t)
+
+  view := df.IndexView([]int{2, 0})
+  u.AssertFloatSliceEquals("ints", cache.VecDense(view, "ints").RawVector().Data, []float64{2, 0}, t)
+  u.AssertFloatSliceEquals("bools", cache.VecDense(view, "bools").RawVector().Data, []float64{1, 1}, t)
+  u.AssertFloatSliceEquals("copy", view.Bools("bools").VecDenseCopy().RawVector().Data, []float64{1, 1}, t)
+
+  df.Ints("ints").Set(0, 10)
+  cache.Invalidate("ints")
+  u.AssertFloatEquals("invalidated", cache.VecDense(df, "ints").AtVec(0), 10, t)
+}
+
+func TestConversionCacheBatches(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  ints := make([]int, 100)
+  for i := range ints {
+    ints[i] = i
+  }
+  df := builder.AddInts("ints", ints...).ToDataFrame()
+  cache := NewConversionCache()
+  batches := df.SplitView(25)
+  first := make([]*mat.VecDense, len(batches))
+  for b, batch := range batches {
+    first[b] = cache.VecDense(batch, "ints")
+  }
+  // second epoch
+  for b, batch := range batches {
+    v := cache.VecDense(batch, "ints")
+    u.AssertTrue("cache hit", &v.RawVector().Data[0] == &first[b].RawVector().Data[0], t)
+    u.AssertFloatEquals("value", v.AtVec(0), float64(25 * b), t)
+  }
+}
+
+func TestConversionCacheReplacedColumn(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddBools("bools", true, false, true, false)
+  df := builder.AddInts("ints", 0, 1, 2, 3).ToDataFrame()
+  cache := NewConversionCache()
+  u.AssertFloatEquals("original", cache.VecDense(df, "ints").AtVec(1), 1, t)
+
+  // same name, same size, different data
+  for k := 0; k < 10; k++ {
+    df.Drop("ints")
+    df.AllocInts("ints")
+    df.Ints("ints").Set(1, k + 10)
+    u.AssertFloatEquals("replaced", cache.VecDense(df, "ints").AtVec(1), float64(k + 10), t)
+  }
+
+  // bounded size
+  for k := 0; k < 2 * MaxCachedConversions; k++ {
+    cache.VecDense(df.Copy(), "ints")
+  }
+  u.AssertTrue("bounded", len(cache.entries) <= MaxCachedConversions, t)
+}
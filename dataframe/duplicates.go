@@ -0,0 +1,205 @@
+package dataframe
+
+import (
+  "fmt"
+  "math"
+  "sort"
+  "hash/fnv"
+)
+
+// offset and prime of the 64-bit FNV hash, used to combine the hashes of
+// multiple columns into a single row hash
+const (
+  rowHashOffset = uint64(14695981039346656037)
+  rowHashPrime  = uint64(1099511628211)
+)
+
+// rowGroups assigns every row of the dataframe to a group of rows that have the
+// exact same values in the given columns.
+// groupOf[j] is the group of the j-th row.
+// firsts[g] is the position of the first row of group g.
+// counts[g] is the number of rows in group g.
+// Groups are numbered in order of first appearance.
+// NaN floats are considered equal to each other, and so are nil objects.
+func (df *DataFrame) rowGroups(columns []string) (groupOf []int, firsts []int, counts []int) {
+  hashes := df.rowHashes(columns)
+  groupOf = make([]int, len(df.indices))
+  buckets := make(map[uint64][]int)
+  for j, h := range hashes {
+    group := -1
+    for _, candidate := range buckets[h] {
+      if df.rowsEqual(columns, df.indices[firsts[candidate]], df.indices[j]) {
+        group = candidate
+        break
+      }
+    }
+    if group < 0 {
+      // new group
+      group = len(firsts)
+      firsts = append(firsts, j)
+      counts = append(counts, 0)
+      buckets[h] = append(buckets[h], group)
+    }
+    groupOf[j] = group
+    counts[group]++
+  }
+  return groupOf, firsts, counts
+}
+
+// rowHashes computes a hash of each row restricted to the given columns.
+// It will panic if a column doesn't exist or is an object column that is not
+// marked as string.
+func (df *DataFrame) rowHashes(columns []string) []uint64 {
+  hashes := make([]uint64, len(df.indices))
+  for j := range hashes {
+    hashes[j] = rowHashOffset
+  }
+  for _, col := range columns {
+    if vals, ok := df.ints[col]; ok {
+      for j, i := range df.indices {
+        hashes[j] = (hashes[j] ^ uint64(vals[i])) * rowHashPrime
+      }
+    } else if vals, ok := df.floats[col]; ok {
+      for j, i := range df.indices {
+        hashes[j] = (hashes[j] ^ floatBits(vals[i])) * rowHashPrime
+      }
+    } else if vals, ok := df.bools[col]; ok {
+      for j, i := range df.indices {
+        var b uint64
+        if vals[i] {
+          b = 1
+        }
+        hashes[j] = (hashes[j] ^ b) * rowHashPrime
+      }
+    } else if vals, ok := df.objects[col]; ok {
+      if !df.stringHeader.contains(col) {
+        panic(fmt.Sprintf("%s is an object column but not marked as string", col))
+      }
+      hash := fnv.New64a()
+      for j, i := range df.indices {
+        var h uint64
+        if vals[i] != nil {
+          hash.Reset()
+          hash.Write([]byte(vals[i].(string)))
+          h = hash.Sum64()
+        }
+        hashes[j] = (hashes[j] ^ h) * rowHashPrime
+      }
+    } else {
+      panic(fmt.Sprintf("column %s does not exist", col))
+    }
+  }
+  return hashes
+}
+
+// rowsEqual compares two rows of the backing data, i.e. i1 and i2 are not
+// positions in the view.
+func (df *DataFrame) rowsEqual(columns []string, i1 int, i2 int) bool {
+  if i1 == i2 {
+    return true
+  }
+  for _, col := range columns {
+    if vals, ok := df.ints[col]; ok {
+      if vals[i1] != vals[i2] {
+        return false
+      }
+    } else if vals, ok := df.floats[col]; ok {
+      if floatBits(vals[i1]) != floatBits(vals[i2]) {
+        return false
+      }
+    } else if vals, ok := df.bools[col]; ok {
+      if vals[i1] != vals[i2] {
+        return false
+      }
+    } else if vals, ok := df.objects[col]; ok {
+      if vals[i1] != vals[i2] {
+        return false
+      }
+    }
+  }
+  return true
+}
+
+// floatBits returns the bits of a float such that all NaNs have the same bits
+// and 0 and -0 have the same bits.
+func floatBits(v float64) uint64 {
+  if math.IsNaN(v) {
+    return math.Float64bits(math.NaN())
+  }
+  if v == 0 {
+    return 0
+  }
+  return math.Float64bits(v)
+}
+
+// DropDuplicatesView removes the rows that have the same values as another row
+// in the given columns.
+// If no column is given, all the columns are compared.
+// If keepLast is false, the first occurrence of each duplicated row is kept.
+// Otherwise, the last occurrence is kept. In both cases, the remaining rows
+// are in the same order as in the original dataframe.
+// The columns can be int, float, bool or string columns. It will panic if a
+// column is neither of those.
+// Missing values are considered equal to each other.
+func (df *DataFrame) DropDuplicatesView(keepLast bool, columns ...string) *DataFrame {
+  if len(columns) == 0 {
+    columns = df.Header().NameList()
+  }
+  groupOf, firsts, _ := df.rowGroups(columns)
+  if !keepLast {
+    return df.IndexView(firsts)
+  }
+  lasts := make([]int, len(firsts))
+  for j, group := range groupOf {
+    lasts[group] = j
+  }
+  sort.Ints(lasts)
+
+  return df.IndexView(lasts)
+}
+
+// Unique returns a dataframe with a single column that contains the distinct
+// values of the given column, ordered by first appearance.
+// The data is copied, so the returned dataframe doesn't share any data with
+// the original dataframe.
+// The column can be an int, float, bool or string column. It will panic if the
+// column is neither of those.
+func (df *DataFrame) Unique(colName string) *DataFrame {
+  _, firsts, _ := df.rowGroups([]string{colName})
+  return df.ColumnView(colName).IndexView(firsts).Copy()
+}
+
+// ValueCounts counts how many times each distinct combination of values occurs
+// in the given columns.
+// It returns a new dataframe with the given columns and an integer column
+// named "count", sorted by descending frequency. Combinations that occur the
+// same number of times are ordered by first appearance.
+// The data is copied, so the returned dataframe doesn't share any data with
+// the original dataframe.
+// The columns can be int, float, bool or string columns. It will panic if a
+// column is neither of those, or if one of the columns is named "count".
+func (df *DataFrame) ValueCounts(columns ...string) *DataFrame {
+  for _, col := range columns {
+    if col == "count" {
+      panic("ValueCounts cannot be called on a column named 'count'")
+    }
+  }
+  _, firsts, counts := df.rowGroups(columns)
+  order := make([]int, len(firsts))
+  for g := range order {
+    order[g] = g
+  }
+  sort.SliceStable(order, func(a, b int) bool {
+    return counts[order[a]] > counts[order[b]]
+  })
+  rows := make([]int, len(order))
+  sortedCounts := make([]int, len(order))
+  for k, g := range order {
+    rows[k] = firsts[g]
+    sortedCounts[k] = counts[g]
+  }
+  result := df.ColumnView(columns...).IndexView(rows).Copy()
+  result.ints["count"] = sortedCounts
+
+  return result
+}
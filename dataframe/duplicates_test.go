@@ -0,0 +1,55 @@
+package dataframe
+
+import (
+    "testing"
+    "math"
+    u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func TestDropDuplicatesView(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddInts("col1", 1, 2, 1, 1, 2)
+  builder.AddObjects("col2", "a", "b", "a", nil, "b").MarkAsString("col2")
+  builder.AddFloats("col3", 0, 1, 2, 3, 4)
+  df := builder.ToDataFrame()
+
+  first := df.DropDuplicatesView(false, "col1", "col2")
+  first.CheckConsistency(t)
+  u.AssertFloatSliceEquals("keep first", first.Floats("col3").VecDenseCopy().RawVector().Data, []float64{0, 1, 3}, t)
+
+  last := df.DropDuplicatesView(true, "col1", "col2")
+  last.CheckConsistency(t)
+  u.AssertFloatSliceEquals("keep last", last.Floats("col3").VecDenseCopy().RawVector().Data, []float64{2, 3, 4}, t)
+
+  all := df.DropDuplicatesView(false)
+  u.AssertIntEquals("all columns", all.NumRows(), 5, t)
+}
+
+func TestDropDuplicatesViewNaN(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("col", math.NaN(), 1, math.NaN(), 0, math.Copysign(0, -1))
+  builder.AddBools("b", true, true, true, false, false)
+  df := builder.ToDataFrame().ReverseView()
+  u.AssertIntEquals("num rows", df.DropDuplicatesView(false).NumRows(), 3, t)
+}
+
+func TestUnique(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddInts("col", 3, 1, 3, 2, 1)
+  df := fillBlanks(builder)
+  unique := df.Unique("col")
+  unique.CheckConsistency(t)
+  u.AssertIntEquals("num columns", unique.NumColumns(), 1, t)
+  u.AssertIntSliceEquals("unique", unique.ints["col"], []int{3, 1, 2}, t)
+}
+
+func TestValueCounts(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddStrings("col", "a", "b", "c", "b", "c", "c", "d")
+  df := fillBlanks(builder).SliceView(1, 7)
+  counts := df.ValueCounts("col")
+  counts.CheckConsistency(t)
+  u.AssertIntSliceEquals("counts", counts.ints["count"], []int{3, 2, 1}, t)
+  u.AssertStringEquals("most frequent", counts.Strings("col").Get(0), "c", t)
+  u.AssertStringEquals("least frequent", counts.Strings("col").Get(2), "d", t)
+}
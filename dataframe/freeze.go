@@ -0,0 +1,96 @@
+package dataframe
+
+import (
+  "fmt"
+)
+
+// Freeze returns a read-only view on the dataframe, which is meant to be
+// shared among goroutines, e.g. a lookup table in a server.
+// The data of the frozen dataframe cannot be altered: column accessors' Set,
+// Overwrite* functions and DataFrameInternals will panic if they attempt to
+// write into a frozen column. Its structure cannot be altered in-place either,
+// so Rename, Drop, Alloc* and so on will panic too.
+// Views on a frozen dataframe inherit the frozen columns, but their own
+// structure can be altered, e.g.
+//  view := frozen.View()
+//  view.Rename("age", "years") // fine, frozen is not affected
+//  view.Floats("years").Set(0, 42) // panics
+// Freeze doesn't copy the data, so the data can still be altered through df
+// and its other non-frozen views.
+// Call Thaw or DetachedView to obtain a writable dataframe.
+func (df *DataFrame) Freeze() *DataFrame {
+  df.debugPrint("freezing")
+  result := df.View()
+  result.frozen = df.Header().Copy()
+  result.frozenMaps = true
+  // the pre-allocated mask cannot be shared among goroutines
+  result.mThreadSafe = true
+  result.debugPrint("Freeze() returns")
+
+  return result
+}
+
+// Thaw returns a writable copy of the dataframe.
+// Unlike DetachedView, it copies the data even if the dataframe is not frozen.
+func (df *DataFrame) Thaw() *DataFrame {
+  df.debugPrint("thawing")
+  result := df.Copy()
+  result.textEncoding = df.textEncoding
+  result.debugPrint("Thaw() returns")
+
+  return result
+}
+
+// IsFrozen returns true if the data of at least one column cannot be altered,
+// which is the case of dataframes returned by Freeze and their views.
+func (df *DataFrame) IsFrozen() bool {
+  return df.frozen.Num() > 0
+}
+
+// CheckWritable returns an error if the structure of df cannot be altered
+// in-place, as is the case of dataframes returned by Freeze, or if the data of
+// one of the given columns is frozen.
+// In-place operations call it before starting any work, so that they don't
+// panic halfway through.
+func (df *DataFrame) CheckWritable(columns ...string) error {
+  if df.frozenMaps {
+    return fmt.Errorf("frozen dataframes cannot be altered in-place. Call View() first")
+  }
+  for _, col := range columns {
+    if df.frozen.contains(col) {
+      return fmt.Errorf("column %s is frozen", col)
+    }
+  }
+  return nil
+}
+
+// checkWritable panics if the data of the given column is frozen.
+func (data *RawData) checkWritable(colName string) {
+  if data.frozen.contains(colName) {
+    panic(fmt.Sprintf("column %s is frozen", colName))
+  }
+}
+
+// checkMutableMaps panics if the structure of the dataframe is frozen.
+func (data *RawData) checkMutableMaps() {
+  if data.frozenMaps {
+    panic("frozen dataframes cannot be altered in-place. Call View() first")
+  }
+}
+
+// freezeColumns and thawColumns never alter the frozen header in-place since
+// it is shared with the views.
+func (data *RawData) freezeColumns(columns ...string) {
+  frozen := data.frozen.Copy()
+  frozen.add(columns...)
+  data.frozen = frozen
+}
+
+func (data *RawData) thawColumns(columns ...string) {
+  if data.frozen.Num() == 0 {
+    return
+  }
+  frozen := data.frozen.Copy()
+  frozen.remove(columns...)
+  data.frozen = frozen
+}
@@ -0,0 +1,80 @@
+package dataframe
+
+import (
+    "testing"
+    u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func panics(f func()) (result bool) {
+  defer func() {
+    result = recover() != nil
+  }()
+  f()
+  return false
+}
+
+func TestFreezeWrites(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("floats", 1, 2, 3)
+  builder.AddInts("ints", 1, 2, 3)
+  builder.AddStrings("strings", "a", "b", "c")
+  frozen := builder.ToDataFrame().Freeze()
+  u.AssertTrue("frozen", frozen.IsFrozen(), t)
+
+  u.AssertTrue("float set", panics(func() { frozen.Floats("floats").Set(0, 5) }), t)
+  u.AssertTrue("int set", panics(func() { frozen.Ints("ints").Set(0, 5) }), t)
+  u.AssertTrue("string set", panics(func() { frozen.Strings("strings").Set(0, "d") }), t)
+  u.AssertTrue("overwrite", panics(func() { frozen.OverwriteFloats64("floats", []float64{4, 5, 6}) }), t)
+  u.AssertTrue("internals", panics(func() { DataFrameInternals{DF: frozen}.FloatData("floats") }), t)
+  u.AssertTrue("rename", panics(func() { frozen.Rename("ints", "integers") }), t)
+  u.AssertTrue("alloc", panics(func() { frozen.AllocFloats("new") }), t)
+  u.AssertTrue("encode", frozen.Encode(nil) == nil, t) // no-op
+  u.AssertTrue("check structure", frozen.CheckWritable() != nil, t)
+  u.AssertFloatEquals("unchanged", frozen.Floats("floats").Get(0), 1, t)
+
+  // views inherit the frozen columns but not the frozen structure
+  view := frozen.SliceView(1, 3)
+  view.Rename("ints", "integers")
+  view.AllocFloats("new")
+  view.Floats("new").Set(0, 42)
+  u.AssertNoError(view.CheckWritable("new"), t)
+  u.AssertTrue("check column", view.CheckWritable("new", "integers") != nil, t)
+  u.AssertTrue("view set", panics(func() { view.Ints("integers").Set(0, 5) }), t)
+  u.AssertTrue("view overwrite", panics(func() { view.OverwriteFloats64("floats", []float64{4, 5}) }), t)
+  view.Drop("floats")
+  view.AllocFloats("floats")
+  view.Floats("floats").Set(0, 42)
+  u.AssertFloatEquals("parent floats", frozen.Floats("floats").Get(1), 2, t)
+  u.AssertFalse("parent header", frozen.Header().NameSet()["integers"], t)
+}
+
+func TestThaw(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("floats", 1, 2, 3)
+  builder.AddInts("ints", 1, 2, 3)
+  frozen := builder.ToDataFrame().Freeze()
+
+  thawed := frozen.Thaw()
+  u.AssertFalse("thawed", thawed.IsFrozen(), t)
+  thawed.Floats("floats").Set(0, 42)
+  thawed.Rename("ints", "integers")
+  u.AssertFloatEquals("parent floats", frozen.Floats("floats").Get(0), 1, t)
+
+  detached := frozen.DetachedView("floats")
+  detached.Floats("floats").Set(0, 42)
+  u.AssertTrue("shared ints", panics(func() { detached.Ints("ints").Set(0, 5) }), t)
+  u.AssertFloatEquals("parent floats", frozen.Floats("floats").Get(0), 1, t)
+  u.AssertFalse("fully detached", frozen.DetachedView().IsFrozen(), t)
+
+  // a frozen dataframe concatenated with another one remains frozen
+  builder = DataBuilder{RawData: NewRawData()}
+  builder.AddBools("bools", false, false, true)
+  other := builder.ToDataFrame()
+  concat, err := ColumnConcatView(frozen, other)
+  u.AssertNoError(err, t)
+  u.AssertTrue("concat frozen", panics(func() { concat.Ints("ints").Set(0, 5) }), t)
+  concat.Bools("bools").Set(0, true)
+  concat, err = ColumnConcatView(other, frozen)
+  u.AssertNoError(err, t)
+  u.AssertTrue("concat frozen", panics(func() { concat.Ints("ints").Set(0, 5) }), t)
+}
@@ -1,6 +1,7 @@
 package dataframe
 
 import (
+  "fmt"
   "golang.org/x/text/encoding"
 )
 
@@ -41,6 +42,14 @@ func (df *DataFrame) Encode(newEncoding encoding.Encoding) error {
   if df.textEncoding == newEncoding {
     return nil
   }
+  if df.frozenMaps {
+    return fmt.Errorf("frozen dataframes cannot be encoded in-place")
+  }
+  for _, col := range df.stringHeader.NameList() {
+    if df.frozen.contains(col) {
+      return fmt.Errorf("column %s is frozen", col)
+    }
+  }
   var decoder *encoding.Decoder
   var encoder *encoding.Encoder
   if df.textEncoding != nil {
@@ -87,6 +96,7 @@ func (df *DataFrame) Encode(newEncoding encoding.Encoding) error {
 //  }
 func (df *DataFrame) OverwriteInts(colName string, values []int) {
   df.debugPrint("overwriting ints on")
+  df.checkWritable(colName)
   col := df.ints[colName]
   if len(col) == 0 {
     df.AllocInts(colName)
@@ -107,6 +117,7 @@ func (df *DataFrame) OverwriteInts(colName string, values []int) {
 //  }
 func (df *DataFrame) OverwriteFloats64(colName string, values []float64) {
   df.debugPrint("overwriting floats64 on")
+  df.checkWritable(colName)
   col := df.floats[colName]
   if len(col) == 0 {
     df.AllocFloats(colName)
@@ -127,6 +138,7 @@ func (df *DataFrame) OverwriteFloats64(colName string, values []float64) {
 //  }
 func (df *DataFrame) OverwriteFloats32(colName string, values []float32) {
   df.debugPrint("overwriting floats32 on")
+  df.checkWritable(colName)
   col := df.floats[colName]
   if len(col) == 0 {
     df.AllocFloats(colName)
@@ -147,6 +159,7 @@ func (df *DataFrame) OverwriteFloats32(colName string, values []float32) {
 //  }
 func (df *DataFrame) OverwriteBools(colName string, values []bool) {
   df.debugPrint("overwriting bools on")
+  df.checkWritable(colName)
   col := df.bools[colName]
   if len(col) == 0 {
     df.AllocBools(colName)
@@ -171,6 +184,7 @@ func (df *DataFrame) OverwriteBools(colName string, values []bool) {
 func (df *DataFrame) OverwriteObjects(colName string, values []interface{},
                                       objectType ObjectType) {
   df.debugPrint("overwriting objects on")
+  df.checkWritable(colName)
   col := df.objects[colName]
   if len(col) == 0 {
     if objectType == 1 {
@@ -198,6 +212,7 @@ func (df *DataFrame) OverwriteObjects(colName string, values []interface{},
 // instead.
 func (df *DataFrame) OverwriteStrings(colName string, values []string) {
   df.debugPrint("overwriting strings on")
+  df.checkWritable(colName)
   col := df.objects[colName]
   if len(col) == 0 {
     df.AllocStrings(colName)
@@ -239,5 +254,6 @@ func (df *DataFrame) cut(from int, to int) *DataFrame {
     }
   }
   df.debugPrint("cut() returns")
+  df.debugValidate("cut()")
   return df
 }
@@ -0,0 +1,317 @@
+package dataframe
+
+import (
+  "bufio"
+  "bytes"
+  "fmt"
+  "io"
+  "math"
+  "sort"
+  "strconv"
+  "strings"
+  "sync"
+)
+
+type LibSVMReadingSpec struct {
+  // This is to multi-thread the parsing of the lines.
+  // Zero and negative values mean ALL cpus on your machine.
+  // The created RawData will also inherit from this value.
+  MaxCPU int
+
+  // Name of the column storing the labels. Default: "target".
+  TargetColumn string
+
+  // Prefix of the feature columns, e.g. "f" to name the features "f1", "f2"...
+  // Default: no prefix, the features are named "1", "2"...
+  FeaturePrefix string
+
+  // If positive, all the features from 1 to NumFeatures are created, even if
+  // they never appear in the data. Otherwise, only the features that appear
+  // at least once are created.
+  NumFeatures int
+}
+
+// libsvmLine is the parsed content of one line of a LibSVM file.
+type libsvmLine struct {
+  label   float64
+  indices []int
+  values  []float64
+}
+
+// parseLibSVMLine parses a line of the form "label idx:val idx:val ...".
+// It returns ok=false if the line is empty or only made of a comment.
+func parseLibSVMLine(line string) (result libsvmLine, ok bool, err error) {
+  if pos := strings.IndexByte(line, '#'); pos >= 0 {
+    line = line[:pos]
+  }
+  fields := strings.Fields(line)
+  if len(fields) == 0 {
+    return result, false, nil
+  }
+  result.label, err = strconv.ParseFloat(fields[0], 64)
+  if err != nil {
+    return result, false, fmt.Errorf("invalid label %q", fields[0])
+  }
+  for _, field := range fields[1:] {
+    sep := strings.IndexByte(field, ':')
+    if sep < 0 {
+      return result, false, fmt.Errorf("invalid feature %q", field)
+    }
+    if field[:sep] == "qid" {
+      continue  // query ids are not supported
+    }
+    index, err := strconv.Atoi(field[:sep])
+    if err != nil || index < 0 {
+      return result, false, fmt.Errorf("invalid feature index %q", field[:sep])
+    }
+    value, err := strconv.ParseFloat(field[sep+1:], 64)
+    if err != nil {
+      return result, false, fmt.Errorf("invalid feature value %q", field[sep+1:])
+    }
+    result.indices = append(result.indices, index)
+    result.values = append(result.values, value)
+  }
+  return result, true, nil
+}
+
+func parseLibSVMLines(lines []string, offset int, parsed []libsvmLine, valid []bool, errs []error, wg *sync.WaitGroup) {
+  defer wg.Done()
+  for i, line := range lines {
+    var err error
+    parsed[i], valid[i], err = parseLibSVMLine(line)
+    if err != nil {
+      errs[0] = fmt.Errorf("line %d: %s", offset + i + 1, err.Error())
+      return
+    }
+  }
+}
+
+// FromLibSVM reads data in LibSVM/SVMlight format and returns a RawData
+// structure with a float target column and one float column per feature.
+// Feature columns are named after their index, preceded by
+// spec.FeaturePrefix. LibSVM data being sparse, the features absent from a
+// line are set to zero.
+// Empty lines, comments starting with '#' and query ids are ignored.
+// It returns an error if a line is malformed or if the reader fails.
+func FromLibSVM(r io.Reader, spec LibSVMReadingSpec) (*RawData, error) {
+  if len(spec.TargetColumn) == 0 {
+    spec.TargetColumn = "target"
+  }
+  // read all the lines
+  var lines []string
+  scanner := bufio.NewScanner(r)
+  scanner.Buffer(make([]byte, 64 * 1024), math.MaxInt32)
+  for scanner.Scan() {
+    lines = append(lines, scanner.Text())
+  }
+  if err := scanner.Err(); err != nil {
+    return nil, err
+  }
+
+  // parse the lines in parallel
+  result := NewRawData()
+  result.SetMaxCPU(spec.MaxCPU)
+  numWorkers := result.ActualMaxCPU()
+  if numWorkers > len(lines) {
+    numWorkers = len(lines)
+  }
+  parsed := make([]libsvmLine, len(lines))
+  valid := make([]bool, len(lines))
+  errs := make([]error, numWorkers)
+  var wg sync.WaitGroup
+  wg.Add(numWorkers)
+  for w := 0; w < numWorkers; w++ {
+    start := w * len(lines) / numWorkers
+    end := (w + 1) * len(lines) / numWorkers
+    go parseLibSVMLines(lines[start:end], start, parsed[start:end], valid[start:end], errs[w:w+1], &wg)
+  }
+  wg.Wait()
+  for _, err := range errs {
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  // put everything together
+  numRows := 0
+  for _, ok := range valid {
+    if ok {
+      numRows++
+    }
+  }
+  name := func(index int) string {
+    return spec.FeaturePrefix + strconv.Itoa(index)
+  }
+  for index := 1; index <= spec.NumFeatures; index++ {
+    result.floats[name(index)] = make([]float64, numRows)
+  }
+  target := make([]float64, numRows)
+  row := 0
+  for i, line := range parsed {
+    if !valid[i] {
+      continue
+    }
+    target[row] = line.label
+    for j, index := range line.indices {
+      col := name(index)
+      vals, ok := result.floats[col]
+      if !ok {
+        vals = make([]float64, numRows)
+        result.floats[col] = vals
+      }
+      vals[row] = line.values[j]
+    }
+    row++
+  }
+  if _, ok := result.floats[spec.TargetColumn]; ok {
+    return nil, fmt.Errorf("target column %s collides with a feature column", spec.TargetColumn)
+  }
+  result.floats[spec.TargetColumn] = target
+  result.dataUID = generateDataUID()
+  result.resetStructureUID()
+
+  return result, nil
+}
+
+// libsvmIndices returns the feature index of each column: the integer ending
+// the column name if all the columns end with distinct positive integers,
+// or their position in the list otherwise, starting from 1.
+func libsvmIndices(featureCols []string) []int {
+  result := make([]int, len(featureCols))
+  seen := make(map[int]bool)
+  for i, col := range featureCols {
+    start := len(col)
+    for start > 0 && col[start-1] >= '0' && col[start-1] <= '9' {
+      start--
+    }
+    index, err := strconv.Atoi(col[start:])
+    if err != nil || index <= 0 || seen[index] {
+      // fallback to positions
+      for j := range result {
+        result[j] = j + 1
+      }
+      return result
+    }
+    seen[index] = true
+    result[i] = index
+  }
+  return result
+}
+
+// numericGetter returns a function reading the given float, int or bool column
+// as floats. Missing ints are read as NaN.
+func (df *DataFrame) numericGetter(col string) func(i int) float64 {
+  switch df.columnKind(col) {
+  case FloatKind:
+    return df.Floats(col).Get
+  case IntKind:
+    access := df.Ints(col)
+    return func(i int) float64 {
+      if v := access.Get(i); v != -1 {
+        return float64(v)
+      }
+      return math.NaN()
+    }
+  default:
+    access := df.Bools(col)
+    return func(i int) float64 {
+      if access.Get(i) {
+        return 1
+      }
+      return 0
+    }
+  }
+}
+
+// formatLibSVM writes the rows of the dataframe into the buffer.
+func (df *DataFrame) formatLibSVM(buf *bytes.Buffer, targetCol string, featureCols []string, indices []int, errs []error, wg *sync.WaitGroup) {
+  defer wg.Done()
+  if df.NumRows() == 0 {
+    return  // SplitNView's filler dataframes have no columns
+  }
+  target := df.numericGetter(targetCol)
+  features := make([]func(int) float64, len(featureCols))
+  for j, col := range featureCols {
+    features[j] = df.numericGetter(col)
+  }
+  for i := 0; i < df.NumRows(); i++ {
+    label := target(i)
+    if math.IsNaN(label) {
+      errs[0] = fmt.Errorf("missing value in target column %s", targetCol)
+      return
+    }
+    buf.WriteString(strconv.FormatFloat(label, 'g', -1, 64))
+    for j, get := range features {
+      v := get(i)
+      if v == 0 || math.IsNaN(v) {
+        continue
+      }
+      buf.WriteByte(' ')
+      buf.WriteString(strconv.Itoa(indices[j]))
+      buf.WriteByte(':')
+      buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+    }
+    buf.WriteByte('\n')
+  }
+}
+
+// ToLibSVM writes the dataframe in LibSVM format into the given writer.
+// The target and the features can be float, int or bool columns.
+// Zeros and missing values, i.e. NaN floats and -1 ints, are skipped, hence
+// missing values are read back as zeros.
+// If all the feature columns end with distinct positive integers, e.g. "3" or
+// "f3" as created by FromLibSVM, these integers are used as feature indices.
+// Otherwise the features are numbered from 1 in the order of featureCols.
+// Features are written in ascending order of their index, as required by the
+// format.
+// The rows are formatted in parallel but written in order.
+// It returns an error if a column doesn't exist or isn't numerical, if the
+// target has missing values, including -1 in an int target column, or if the
+// writer fails.
+func (df *DataFrame) ToLibSVM(w io.Writer, targetCol string, featureCols []string) error {
+  df.debugPrint("writing LibSVM data of")
+  for _, col := range append([]string{targetCol}, featureCols...) {
+    switch df.columnKind(col) {
+    case FloatKind, IntKind, BoolKind:
+    case "":
+      return fmt.Errorf("column %s doesn't exist", col)
+    default:
+      return fmt.Errorf("column %s is not numerical", col)
+    }
+  }
+  // the format requires ascending indices
+  indices := libsvmIndices(featureCols)
+  order := make([]int, len(featureCols))
+  for j := range order {
+    order[j] = j
+  }
+  sort.Slice(order, func(a, b int) bool { return indices[order[a]] < indices[order[b]] })
+  sortedCols := make([]string, len(order))
+  sortedIndices := make([]int, len(order))
+  for j, k := range order {
+    sortedCols[j] = featureCols[k]
+    sortedIndices[j] = indices[k]
+  }
+  featureCols, indices = sortedCols, sortedIndices
+
+  parts := df.SplitNView(df.ActualMaxCPU())
+  buffers := make([]bytes.Buffer, len(parts))
+  errs := make([]error, len(parts))
+  var wg sync.WaitGroup
+  wg.Add(len(parts))
+  for i, part := range parts {
+    go part.formatLibSVM(&buffers[i], targetCol, featureCols, indices, errs[i:i+1], &wg)
+  }
+  wg.Wait()
+  for _, err := range errs {
+    if err != nil {
+      return err
+    }
+  }
+  for i := range buffers {
+    if _, err := buffers[i].WriteTo(w); err != nil {
+      return err
+    }
+  }
+  return nil
+}
@@ -0,0 +1,79 @@
+package dataframe
+
+import (
+  "bytes"
+  "strings"
+  "testing"
+  u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func TestFromLibSVM(t *testing.T) {
+  content := "# comment\n1 3:0.5 7:2\n\n-1 qid:4 1:1.5 # trailing\n0\n"
+  data, err := FromLibSVM(strings.NewReader(content), LibSVMReadingSpec{FeaturePrefix: "f"})
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  df := data.ToDataFrame()
+  df.CheckConsistency(t)
+  u.AssertIntEquals("num rows", df.NumRows(), 3, t)
+  u.AssertStringSliceEquals("columns", df.FloatHeader().NameList(), []string{"target", "f1", "f3", "f7"}, false, t)
+  u.AssertFloatEquals("target", df.Floats("target").Get(1), -1, t)
+  u.AssertFloatEquals("f7", df.Floats("f7").Get(0), 2, t)
+  u.AssertFloatEquals("absent", df.Floats("f7").Get(1), 0, t)
+
+  data, err = FromLibSVM(strings.NewReader(content), LibSVMReadingSpec{NumFeatures: 10})
+  u.AssertNoError(err, t)
+  u.AssertIntEquals("num features", data.FloatHeader().Num(), 11, t)
+
+  _, err = FromLibSVM(strings.NewReader("1 3=0.5\n"), LibSVMReadingSpec{})
+  u.AssertTrue("malformed", err != nil, t)
+}
+
+func TestToLibSVM(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddInts("label", 1, 0, 1)
+  builder.AddFloats("f2", 0, 1.5, 0)
+  builder.AddBools("f5", true, false, false)
+  df := builder.ToDataFrame()
+
+  var buf bytes.Buffer
+  u.AssertNoError(df.ToLibSVM(&buf, "label", []string{"f2", "f5"}), t)
+  u.AssertStringEquals("content", buf.String(), "1 5:1\n0 2:1.5\n1\n", t)
+
+  // round trip
+  data, err := FromLibSVM(&buf, LibSVMReadingSpec{FeaturePrefix: "f", NumFeatures: 5})
+  u.AssertNoError(err, t)
+  u.AssertFloatEquals("f2", data.ToDataFrame().Floats("f2").Get(1), 1.5, t)
+
+  // positions
+  builder = DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("label", 1)
+  builder.AddFloats("height", 3)
+  builder.AddFloats("width", 4)
+  buf.Reset()
+  u.AssertNoError(builder.ToDataFrame().ToLibSVM(&buf, "label", []string{"width", "height"}), t)
+  u.AssertStringEquals("positions", buf.String(), "1 1:4 2:3\n", t)
+
+  u.AssertTrue("unknown", df.ToLibSVM(&buf, "label", []string{"f3"}) != nil, t)
+}
+
+func TestToLibSVMOrder(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddInts("label", 1, -1)
+  builder.AddFloats("f10", 2, 0)
+  builder.AddFloats("f2", 3, 0)
+  df := builder.ToDataFrame()
+
+  var buf bytes.Buffer
+  u.AssertNoError(df.SliceView(0, 1).ToLibSVM(&buf, "label", []string{"f10", "f2"}), t)
+  u.AssertStringEquals("ascending", buf.String(), "1 2:3 10:2\n", t)
+
+  // missing int target
+  buf.Reset()
+  u.AssertTrue("missing target", df.ToLibSVM(&buf, "label", []string{"f10", "f2"}) != nil, t)
+
+  // missing int feature
+  buf.Reset()
+  u.AssertNoError(df.ToLibSVM(&buf, "f2", []string{"label"}), t)
+  u.AssertStringEquals("missing feature", buf.String(), "3 1:1\n0\n", t)
+}
@@ -0,0 +1,237 @@
+package dataframe
+
+import (
+  "bufio"
+  "encoding/binary"
+  "encoding/json"
+  "fmt"
+  "io"
+  "io/ioutil"
+  "os"
+  "path/filepath"
+  "reflect"
+  "strconv"
+  "unsafe"
+)
+
+// name of the metadata file of a mapped directory
+const mappedMetadataFile = "metadata.json"
+
+// mappedMetadata describes the content of a directory written by WriteMapped.
+type mappedMetadata struct {
+  NumRows   int
+  // size of the integers, in bits
+  IntSize   int
+  ByteOrder string
+  Columns   []mappedColumn
+}
+
+type mappedColumn struct {
+  Name string
+  // one of "float", "int" or "bool"
+  Kind string
+  File string
+}
+
+// nativeByteOrder returns "little" or "big" depending on the endianness of
+// the machine.
+func nativeByteOrder() string {
+  x := uint16(1)
+  if *(*byte)(unsafe.Pointer(&x)) == 1 {
+    return "little"
+  }
+  return "big"
+}
+
+// WriteMapped writes the float, int and bool columns of the dataframe into the
+// given directory, one file per column plus a metadata file, so that the
+// dataframe can be loaded back with OpenMapped.
+// Only the rows of the view are written. Columns are written in the byte order
+// of the machine, as 8-byte floats, IntSize-bit integers and 1-byte bools.
+// The directory is created if it doesn't exist.
+// It returns an error if the dataframe has object columns, including string
+// columns, or if an I/O error occurred.
+func (df *DataFrame) WriteMapped(dir string) error {
+  df.debugPrint("writing mapped columns of")
+  if len(df.objects) > 0 {
+    return fmt.Errorf("object and string columns cannot be mapped")
+  }
+  if err := os.MkdirAll(dir, 0755); err != nil {
+    return err
+  }
+  meta := mappedMetadata{
+    NumRows: df.NumRows(),
+    IntSize: strconv.IntSize,
+    ByteOrder: nativeByteOrder(),
+  }
+  var order binary.ByteOrder = binary.LittleEndian
+  if meta.ByteOrder == "big" {
+    order = binary.BigEndian
+  }
+  add := func(col string, kind string, write func(w io.Writer) error) error {
+    file := fmt.Sprintf("%d.%s", len(meta.Columns), kind)
+    meta.Columns = append(meta.Columns, mappedColumn{Name: col, Kind: kind, File: file})
+    f, err := os.Create(filepath.Join(dir, file))
+    if err != nil {
+      return err
+    }
+    w := bufio.NewWriter(f)
+    if err = write(w); err == nil {
+      err = w.Flush()
+    }
+    if closeErr := f.Close(); err == nil {
+      err = closeErr
+    }
+    return err
+  }
+  for _, col := range df.FloatHeader().NameList() {
+    vals := df.floats[col]
+    err := add(col, FloatKind, func(w io.Writer) error {
+      compact := make([]float64, len(df.indices))
+      for j, i := range df.indices {
+        compact[j] = vals[i]
+      }
+      return binary.Write(w, order, compact)
+    })
+    if err != nil {
+      return err
+    }
+  }
+  for _, col := range df.IntHeader().NameList() {
+    vals := df.ints[col]
+    err := add(col, IntKind, func(w io.Writer) error {
+      if strconv.IntSize == 32 {
+        compact := make([]int32, len(df.indices))
+        for j, i := range df.indices {
+          compact[j] = int32(vals[i])
+        }
+        return binary.Write(w, order, compact)
+      }
+      compact := make([]int64, len(df.indices))
+      for j, i := range df.indices {
+        compact[j] = int64(vals[i])
+      }
+      return binary.Write(w, order, compact)
+    })
+    if err != nil {
+      return err
+    }
+  }
+  for _, col := range df.BoolHeader().NameList() {
+    vals := df.bools[col]
+    err := add(col, BoolKind, func(w io.Writer) error {
+      compact := make([]bool, len(df.indices))
+      for j, i := range df.indices {
+        compact[j] = vals[i]
+      }
+      return binary.Write(w, order, compact)
+    })
+    if err != nil {
+      return err
+    }
+  }
+  content, err := json.MarshalIndent(meta, "", "  ")
+  if err != nil {
+    return err
+  }
+  return ioutil.WriteFile(filepath.Join(dir, mappedMetadataFile), content, 0644)
+}
+
+// mappedCloser releases the memory mapped by OpenMapped.
+type mappedCloser struct {
+  regions [][]byte
+}
+
+func (closer *mappedCloser) Close() error {
+  var result error
+  for _, region := range closer.regions {
+    if err := unmapFile(region); err != nil && result == nil {
+      result = err
+    }
+  }
+  closer.regions = nil
+  return result
+}
+
+// OpenMapped loads a directory written by WriteMapped without reading the
+// data into the heap. The column files are memory-mapped, so the data is
+// loaded lazily by the operating system as the rows are accessed.
+// The returned dataframe is frozen since the mapped memory is read-only.
+// Views such as ShuffleView and SplitView work as usual, and Thaw,
+// DetachedView or Copy return writable copies in the heap.
+// The returned Closer unmaps the files. Neither the dataframe nor its views
+// can be used after Close is called, except for copies made beforehand.
+// It returns an error if the directory was written on a machine with a
+// different byte order or integer size, or if an I/O error occurred.
+// On platforms without mmap support, the files are read into the heap.
+func OpenMapped(dir string) (*DataFrame, io.Closer, error) {
+  content, err := ioutil.ReadFile(filepath.Join(dir, mappedMetadataFile))
+  if err != nil {
+    return nil, nil, err
+  }
+  var meta mappedMetadata
+  if err := json.Unmarshal(content, &meta); err != nil {
+    return nil, nil, err
+  }
+  if meta.ByteOrder != nativeByteOrder() {
+    return nil, nil, fmt.Errorf("columns are stored in %s-endian order", meta.ByteOrder)
+  }
+  if meta.IntSize != strconv.IntSize {
+    return nil, nil, fmt.Errorf("integers are stored on %d bits. Expected: %d", meta.IntSize, strconv.IntSize)
+  }
+  closer := &mappedCloser{}
+  data := NewRawData()
+  for _, c := range meta.Columns {
+    var elemSize int
+    switch c.Kind {
+    case FloatKind:
+      elemSize = 8
+    case IntKind:
+      elemSize = strconv.IntSize / 8
+    case BoolKind:
+      elemSize = 1
+    default:
+      closer.Close()
+      return nil, nil, fmt.Errorf("column %s has unknown kind %s", c.Name, c.Kind)
+    }
+    region, err := mapFile(filepath.Join(dir, c.File), meta.NumRows * elemSize)
+    if err != nil {
+      closer.Close()
+      return nil, nil, fmt.Errorf("column %s: %s", c.Name, err.Error())
+    }
+    if region != nil {
+      closer.regions = append(closer.regions, region)
+    }
+    switch c.Kind {
+    case FloatKind:
+      var vals []float64
+      castBytes(unsafe.Pointer(&vals), region, meta.NumRows)
+      data.floats[c.Name] = vals
+    case IntKind:
+      var vals []int
+      castBytes(unsafe.Pointer(&vals), region, meta.NumRows)
+      data.ints[c.Name] = vals
+    case BoolKind:
+      var vals []bool
+      castBytes(unsafe.Pointer(&vals), region, meta.NumRows)
+      data.bools[c.Name] = vals
+    }
+  }
+  df := data.ToDataFrame()
+  if data.NumColumns() == 0 {
+    df = EmptyDataFrame(meta.NumRows, -1)
+  }
+  return df.Freeze(), closer, nil
+}
+
+// castBytes makes the slice pointed by slicePtr point to the given bytes,
+// without copying them.
+func castBytes(slicePtr unsafe.Pointer, region []byte, n int) {
+  if n == 0 {
+    return
+  }
+  header := (*reflect.SliceHeader)(slicePtr)
+  header.Data = uintptr(unsafe.Pointer(&region[0]))
+  header.Len = n
+  header.Cap = n
+}
@@ -0,0 +1,60 @@
+package dataframe
+
+import (
+    "os"
+    "testing"
+    "io/ioutil"
+    u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func TestMappedRoundTrip(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("floats", 1.5, 2.5, 3.5, 4.5)
+  builder.AddInts("ints", 1, -1, 3, 4)
+  builder.AddBools("bools", true, false, true, false)
+  df := builder.ToDataFrame().ReverseView()
+
+  dir, err := ioutil.TempDir("", "mapped")
+  u.AssertNoError(err, t)
+  defer os.RemoveAll(dir)
+  u.AssertNoError(df.WriteMapped(dir), t)
+
+  mapped, closer, err := OpenMapped(dir)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  defer closer.Close()
+  mapped.CheckConsistency(t)
+  u.AssertTrue("frozen", mapped.IsFrozen(), t)
+  u.AssertIntEquals("num rows", mapped.NumRows(), 4, t)
+  u.AssertFloatEquals("floats", mapped.Floats("floats").Get(0), 4.5, t)
+  u.AssertIntEquals("ints", mapped.Ints("ints").Get(2), -1, t)
+  u.AssertFalse("bools", mapped.Bools("bools").Get(0), t)
+
+  // views work on mapped data
+  batches := mapped.ShuffleView().SplitView(3)
+  u.AssertIntEquals("num batches", len(batches), 2, t)
+  total := 0.0
+  for _, batch := range batches {
+    for _, v := range batch.Floats("floats").VecDense().RawVector().Data {
+      total += v
+    }
+  }
+  u.AssertFloatEquals("total", total, 12, t)
+
+  // writable copy
+  thawed := mapped.Thaw()
+  thawed.Floats("floats").Set(0, 42)
+  u.AssertFloatEquals("mapped", mapped.Floats("floats").Get(0), 4.5, t)
+}
+
+func TestMappedErrors(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddStrings("strings", "a", "b")
+  dir, err := ioutil.TempDir("", "mapped")
+  u.AssertNoError(err, t)
+  defer os.RemoveAll(dir)
+  u.AssertTrue("string columns", builder.ToDataFrame().WriteMapped(dir) != nil, t)
+  _, _, err = OpenMapped(dir)
+  u.AssertTrue("no metadata", err != nil, t)
+}
@@ -0,0 +1,121 @@
+package dataframe
+
+import (
+  "strconv"
+)
+
+// ColumnMemory describes the memory used by the backing data of a column.
+type ColumnMemory struct {
+  // Number of bytes of the backing allocation, including the rows that are not
+  // part of the view. For object columns, it includes the size of the strings
+  // but not the size of the other objects.
+  Bytes    int
+  // Number of rows of the backing data, which is usually higher than the
+  // number of rows of a view.
+  Rows     int
+  // Capacity of the backing data. It can be higher than Rows, e.g. if the
+  // column was built by appending values.
+  Capacity int
+  // Whether the data is shared with another dataframe, e.g. a parent
+  // dataframe.
+  Shared   bool
+}
+
+// MemoryUsage is a report of the memory used by a dataframe, as returned by
+// DataFrame.MemoryUsage().
+type MemoryUsage struct {
+  Columns       map[string]ColumnMemory
+  // Bytes of the columns that are not shared with other dataframes.
+  OwnedBytes    int
+  // Bytes of the columns that are shared with other dataframes.
+  SharedBytes   int
+  // Bytes of the slice of indices that makes up the view.
+  IndexBytes    int
+  // Number of rows of the dataframe, i.e. NumRows().
+  ViewedRows    int
+  // Number of rows of the backing data, as returned by NumAllocatedRows().
+  AllocatedRows int
+  // Same as the data UID printed in debug mode. Dataframes with overlapping
+  // data UIDs are likely to share data.
+  DataUID       string
+}
+
+// size of an interface{} header
+const interfaceBytes = 16
+
+// MemoryUsage reports how much memory is used by the dataframe's columns and
+// whether the columns' data is owned by the dataframe or shared with another
+// dataframe.
+// This is an estimation. For instance, strings that are referenced in several
+// rows are counted as many times as they are referenced.
+// MemoryUsage is meant to help you decide when to call Compact().
+func (df *DataFrame) MemoryUsage() MemoryUsage {
+  result := MemoryUsage{
+    Columns: make(map[string]ColumnMemory),
+    IndexBytes: cap(df.indices) * strconv.IntSize / 8,
+    ViewedRows: len(df.indices),
+    DataUID: df.printableDataUID(),
+  }
+  for col, vals := range df.floats {
+    result.add(df, col, len(vals), cap(vals), 8 * cap(vals))
+  }
+  for col, vals := range df.ints {
+    result.add(df, col, len(vals), cap(vals), strconv.IntSize / 8 * cap(vals))
+  }
+  for col, vals := range df.bools {
+    result.add(df, col, len(vals), cap(vals), cap(vals))
+  }
+  for col, vals := range df.objects {
+    bytes := interfaceBytes * cap(vals)
+    for _, v := range vals {
+      if str, ok := v.(string); ok {
+        bytes += len(str)
+      }
+    }
+    result.add(df, col, len(vals), cap(vals), bytes)
+  }
+  return result
+}
+
+func (usage *MemoryUsage) add(df *DataFrame, col string, rows int, capacity int, bytes int) {
+  shared := df.sharedMaps || df.shared.contains(col)
+  usage.Columns[col] = ColumnMemory{Bytes: bytes, Rows: rows, Capacity: capacity, Shared: shared}
+  if shared {
+    usage.SharedBytes += bytes
+  } else {
+    usage.OwnedBytes += bytes
+  }
+  if rows > usage.AllocatedRows {
+    usage.AllocatedRows = rows
+  }
+}
+
+// IsCompact returns true if the dataframe owns all its data and doesn't
+// allocate more rows than it views.
+func (df *DataFrame) IsCompact() bool {
+  if df.indexViewed || df.sharedMaps || df.shared.Num() > 0 {
+    return false
+  }
+  return df.NumAllocatedRows() <= len(df.indices)
+}
+
+// Compact returns a dataframe that contains the same data as df but doesn't
+// share any data with other dataframes and doesn't allocate more rows than
+// NumRows().
+// This lets the garbage collector reclaim the memory of large parent
+// dataframes once they are not referenced anymore, e.g.:
+//  sample := big.SampleView(1000, false).Compact()
+//  big = nil
+// If df is already compact, Compact returns df itself. Otherwise, it returns a
+// copy, which can be expensive if the dataframe has many rows.
+func (df *DataFrame) Compact() *DataFrame {
+  if df.IsCompact() {
+    return df
+  }
+  df.debugPrint("compacting")
+  result := df.Copy()
+  result.textEncoding = df.textEncoding
+  result.debugPrint("Compact() returns")
+
+  return result
+}
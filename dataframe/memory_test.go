@@ -0,0 +1,44 @@
+package dataframe
+
+import (
+    "testing"
+    u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func TestMemoryUsage(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.SetFloats("floats", make([]float64, 100))
+  builder.SetBools("bools", make([]bool, 100))
+  df := builder.ToDataFrame()
+
+  usage := df.MemoryUsage()
+  u.AssertIntEquals("owned", usage.OwnedBytes, 900, t)
+  u.AssertIntEquals("shared", usage.SharedBytes, 0, t)
+  u.AssertIntEquals("allocated", usage.AllocatedRows, 100, t)
+  u.AssertTrue("compact", df.IsCompact(), t)
+
+  view := df.IndexView([]int{1, 2}).DetachedView("bools")
+  usage = view.MemoryUsage()
+  u.AssertIntEquals("owned", usage.OwnedBytes, 100, t)
+  u.AssertIntEquals("shared", usage.SharedBytes, 800, t)
+  u.AssertIntEquals("viewed", usage.ViewedRows, 2, t)
+  u.AssertTrue("shared floats", usage.Columns["floats"].Shared, t)
+  u.AssertFalse("owned bools", usage.Columns["bools"].Shared, t)
+}
+
+func TestCompact(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddInts("col", u.MakeRange(0, 100, 1)...)
+  df := fillBlanks(builder)
+  u.AssertTrue("compact df", df.Compact() == df, t)
+
+  for _, view := range []*DataFrame{df.SliceView(10, 15), df.IndexView([]int{5, 4}), df.View()} {
+    u.AssertFalse("not compact", view.IsCompact(), t)
+    compact := view.Compact()
+    compact.CheckConsistency(t)
+    u.AssertTrue("compact", compact.IsCompact(), t)
+    u.AssertIntEquals("num rows", compact.NumRows(), view.NumRows(), t)
+    u.AssertIntEquals("allocated", compact.MemoryUsage().AllocatedRows, view.NumRows(), t)
+    u.AssertIntEquals("first value", compact.Ints("col").Get(0), view.Ints("col").Get(0), t)
+  }
+}
@@ -0,0 +1,204 @@
+package dataframe
+
+import (
+  "fmt"
+  "math"
+)
+
+// MissingThreshold tells DropMissingView how many missing values a row must
+// have to be dropped.
+// Besides AnyMissing and AllMissing, any positive number is a valid threshold.
+type MissingThreshold int
+
+const(
+  // rows where all the given columns are missing are dropped
+  AllMissing MissingThreshold = 0
+  // rows with at least one missing value are dropped
+  AnyMissing MissingThreshold = 1
+)
+
+// missingFlags returns, for each row of the view, whether the value is missing.
+// Missing values are NaN in float columns, -1 in int columns and nil in object
+// columns. Bool columns never have missing values.
+// It will panic if the column doesn't exist.
+func (df *DataFrame) missingFlags(colName string) []bool {
+  flags := make([]bool, len(df.indices))
+  if vals, ok := df.floats[colName]; ok {
+    for j, i := range df.indices {
+      flags[j] = math.IsNaN(vals[i])
+    }
+  } else if vals, ok := df.ints[colName]; ok {
+    for j, i := range df.indices {
+      flags[j] = vals[i] == -1
+    }
+  } else if vals, ok := df.objects[colName]; ok {
+    for j, i := range df.indices {
+      flags[j] = vals[i] == nil
+    }
+  } else if _, ok := df.bools[colName]; !ok {
+    panic(fmt.Sprintf("column %s does not exist", colName))
+  }
+  return flags
+}
+
+// MissingCounts returns the number of missing values of each column.
+// Missing values are NaN in float columns, -1 in int columns and nil in object
+// columns, including string columns. Bool columns always have zero missing
+// values.
+func (df *DataFrame) MissingCounts() map[string]int {
+  result := make(map[string]int)
+  for col := range df.Header().NameSet() {
+    n := 0
+    for _, missing := range df.missingFlags(col) {
+      if missing {
+        n++
+      }
+    }
+    result[col] = n
+  }
+  return result
+}
+
+// DropMissingView removes the rows that have too many missing values in the
+// given columns.
+// If threshold is AnyMissing, rows with at least one missing value are
+// dropped. If threshold is AllMissing, rows are dropped only if all the given
+// columns are missing. Otherwise, rows with at least threshold missing values
+// are dropped.
+// If no column is given, all the columns are considered.
+// Missing values are NaN in float columns, -1 in int columns and nil in object
+// columns.
+func (df *DataFrame) DropMissingView(threshold MissingThreshold, columns ...string) *DataFrame {
+  if len(columns) == 0 {
+    columns = df.Header().NameList()
+  }
+  n := int(threshold)
+  if threshold == AllMissing {
+    n = len(columns)
+  }
+  counts := make([]int, len(df.indices))
+  for _, col := range columns {
+    for j, missing := range df.missingFlags(col) {
+      if missing {
+        counts[j]++
+      }
+    }
+  }
+  mask := df.EmptyMask()
+  for j, count := range counts {
+    mask[j] = count < n
+  }
+  return df.MaskView(mask)
+}
+
+// FillMissingView replaces the missing values of the columns given as keys of
+// the map with the values of the map.
+// Fill values must be float64 (or int) for float columns, int for int columns,
+// and of any type for object columns, e.g. strings for string columns.
+// Bool columns have no missing values, so they are left untouched.
+// It will panic if a column doesn't exist or if a value is of the wrong type.
+// The filled columns are newly allocated, so the original dataframe is not
+// altered. Other columns share their data with the original dataframe.
+func (df *DataFrame) FillMissingView(values map[string]interface{}) *DataFrame {
+  df.debugPrint("filling missing values")
+  result := df.View()
+  if len(values) == 0 {
+    return result
+  }
+  result.reallocateMaps()
+  nRows := df.NumAllocatedRows()
+  for col, value := range values {
+    if vals, ok := df.floats[col]; ok {
+      var fill float64
+      switch v := value.(type) {
+      case float64:
+        fill = v
+      case int:
+        fill = float64(v)
+      default:
+        panic(fmt.Sprintf("cannot fill float column %s with %v", col, value))
+      }
+      newVals := make([]float64, nRows)
+      for _, i := range df.indices {
+        if math.IsNaN(vals[i]) {
+          newVals[i] = fill
+        } else {
+          newVals[i] = vals[i]
+        }
+      }
+      result.floats[col] = newVals
+    } else if vals, ok := df.ints[col]; ok {
+      fill, valid := value.(int)
+      if !valid {
+        panic(fmt.Sprintf("cannot fill int column %s with %v", col, value))
+      }
+      newVals := make([]int, nRows)
+      for _, i := range df.indices {
+        if vals[i] == -1 {
+          newVals[i] = fill
+        } else {
+          newVals[i] = vals[i]
+        }
+      }
+      result.ints[col] = newVals
+    } else if vals, ok := df.objects[col]; ok {
+      if _, valid := value.(string); !valid && df.stringHeader.contains(col) {
+        panic(fmt.Sprintf("cannot fill string column %s with %v", col, value))
+      }
+      newVals := make([]interface{}, nRows)
+      for _, i := range df.indices {
+        if vals[i] == nil {
+          newVals[i] = value
+        } else {
+          newVals[i] = vals[i]
+        }
+      }
+      result.objects[col] = newVals
+    } else if _, ok := df.bools[col]; ok {
+      continue
+    } else {
+      panic(fmt.Sprintf("column %s does not exist", col))
+    }
+    result.shared.remove(col)
+  }
+  result.dataUID |= generateDataUID()
+  result.debugPrint("FillMissingView() returns")
+
+  result.debugValidate("FillMissingView()")
+  return result
+}
+
+// MissingIndicatorView adds a bool column named <col>_isnull for each of the
+// given columns. The bool column is true where the value of the original column
+// is missing.
+// If no column is given, an indicator is added for every column except bool
+// columns, since bool columns cannot have missing values.
+// Missing values are NaN in float columns, -1 in int columns and nil in object
+// columns.
+// The original dataframe is not altered.
+// It returns an error if a column named <col>_isnull already exists.
+func (df *DataFrame) MissingIndicatorView(columns ...string) (*DataFrame, error) {
+  if len(columns) == 0 {
+    columns = df.Header().ExceptHeader(df.BoolHeader()).NameList()
+  }
+  existing := df.Header().NameSet()
+  for _, col := range columns {
+    if existing[col + "_isnull"] {
+      return nil, fmt.Errorf("column %s_isnull already exists", col)
+    }
+  }
+  result := df.View()
+  result.reallocateMaps()
+  nRows := df.NumAllocatedRows()
+  for _, col := range columns {
+    indicator := make([]bool, nRows)
+    for j, missing := range df.missingFlags(col) {
+      indicator[df.indices[j]] = missing
+    }
+    result.bools[col + "_isnull"] = indicator
+  }
+  result.dataUID |= generateDataUID()
+
+  result.debugValidate("MissingIndicatorView()")
+  return result, nil
+}
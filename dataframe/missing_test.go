@@ -0,0 +1,67 @@
+package dataframe
+
+import (
+    "testing"
+    "math"
+    u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func missingTestingData() *DataFrame {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("floats", 1, math.NaN(), 3, math.NaN())
+  builder.AddInts("ints", -1, 2, 3, -1)
+  builder.AddObjects("strings", "a", nil, "c", "d").MarkAsString("strings")
+  builder.AddBools("bools", true, false, true, false)
+  return builder.ToDataFrame()
+}
+
+func TestMissingCounts(t *testing.T) {
+  counts := missingTestingData().MissingCounts()
+  u.AssertIntEquals("floats", counts["floats"], 2, t)
+  u.AssertIntEquals("ints", counts["ints"], 2, t)
+  u.AssertIntEquals("strings", counts["strings"], 1, t)
+  u.AssertIntEquals("bools", counts["bools"], 0, t)
+}
+
+func TestDropMissingView(t *testing.T) {
+  df := missingTestingData()
+  any := df.DropMissingView(AnyMissing)
+  any.CheckConsistency(t)
+  u.AssertIntEquals("any", any.NumRows(), 1, t)
+
+  all := df.DropMissingView(AllMissing, "floats", "ints")
+  u.AssertIntEquals("all", all.NumRows(), 3, t)
+
+  two := df.DropMissingView(2)
+  u.AssertIntEquals("threshold", two.NumRows(), 2, t)
+}
+
+func TestFillMissingView(t *testing.T) {
+  df := missingTestingData()
+  view := df.SliceView(1, 4).FillMissingView(map[string]interface{}{
+    "floats": 0.5, "ints": 0, "strings": "b", "bools": false,
+  })
+  view.CheckConsistency(t)
+  u.AssertFloatEquals("float fill", view.Floats("floats").Get(0), 0.5, t)
+  u.AssertIntEquals("int fill", view.Ints("ints").Get(2), 0, t)
+  u.AssertStringEquals("string fill", view.Strings("strings").Get(0), "b", t)
+  // the original data is not altered
+  u.AssertTrue("float not altered", math.IsNaN(df.Floats("floats").Get(1)), t)
+  u.AssertIntEquals("int not altered", df.Ints("ints").Get(3), -1, t)
+}
+
+func TestMissingIndicatorView(t *testing.T) {
+  df := missingTestingData()
+  view, err := df.IndexView([]int{3, 0}).MissingIndicatorView()
+  u.AssertNoError(err, t)
+  view.CheckConsistency(t)
+  u.AssertIntEquals("num columns", view.NumColumns(), 7, t)
+  u.AssertTrue("floats_isnull", view.Bools("floats_isnull").Get(0), t)
+  u.AssertFalse("strings_isnull", view.Bools("strings_isnull").Get(0), t)
+  u.AssertTrue("ints_isnull", view.Bools("ints_isnull").Get(1), t)
+  u.AssertIntEquals("original columns", df.NumColumns(), 4, t)
+
+  // the indicators cannot be added twice
+  _, err = view.MissingIndicatorView("floats")
+  u.AssertTrue("existing indicator", err != nil, t)
+}
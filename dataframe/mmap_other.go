@@ -0,0 +1,28 @@
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd
+
+package dataframe
+
+import (
+  "fmt"
+  "io/ioutil"
+)
+
+// mapFile reads the file into the heap since mmap is not supported.
+// It returns an error if the file doesn't have the expected size.
+func mapFile(path string, size int) ([]byte, error) {
+  content, err := ioutil.ReadFile(path)
+  if err != nil {
+    return nil, err
+  }
+  if len(content) != size {
+    return nil, fmt.Errorf("%s has %d bytes. Expected: %d", path, len(content), size)
+  }
+  if size == 0 {
+    return nil, nil
+  }
+  return content, nil
+}
+
+func unmapFile(region []byte) error {
+  return nil
+}
@@ -0,0 +1,34 @@
+// +build linux darwin freebsd netbsd openbsd
+
+package dataframe
+
+import (
+  "fmt"
+  "os"
+  "syscall"
+)
+
+// mapFile maps the given file into read-only memory.
+// It returns an error if the file doesn't have the expected size.
+func mapFile(path string, size int) ([]byte, error) {
+  f, err := os.Open(path)
+  if err != nil {
+    return nil, err
+  }
+  defer f.Close()
+  info, err := f.Stat()
+  if err != nil {
+    return nil, err
+  }
+  if info.Size() != int64(size) {
+    return nil, fmt.Errorf("%s has %d bytes. Expected: %d", path, info.Size(), size)
+  }
+  if size == 0 {
+    return nil, nil  // mmap doesn't support empty mappings
+  }
+  return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+func unmapFile(region []byte) error {
+  return syscall.Munmap(region)
+}
@@ -1,6 +1,7 @@
 package dataframe
 
 import (
+   "os"
    "fmt"
    "sort"
    "log"
@@ -13,49 +14,13 @@ import (
 // Everything is printed on stdout. Nothing on stderr.
 // PrintSummary returns the dataframe itself so you can write
 //  df.PrintSummary().PrintHead(n, "") or df.PrintHead(n, "").PrintSummary()
+// Use RenderSummary to write the summary elsewhere.
 func (df *DataFrame) PrintSummary() *DataFrame {
-  if len(df.floats) > 0 {
-    cols := df.FloatHeader().NameList()
-    sort.Strings(cols)
-    fmt.Printf("float   %s\n", cols)
-  }
-  if len(df.ints) > 0 {
-    cols := df.IntHeader().NameList()
-    sort.Strings(cols)
-    fmt.Printf("int     %s\n", cols)
-  }
-  if len(df.bools) > 0 {
-    cols := df.BoolHeader().NameList()
-    sort.Strings(cols)
-    fmt.Printf("bool    %s\n", cols)
-  }
-  if len(df.objects) > 0 {
-    cols := df.ObjectHeader().ExceptHeader(df.StringHeader()).NameList()
-    sort.Strings(cols)
-    fmt.Printf("object  %s\n", cols)
-  }
-  strings := df.StringHeader().NameList()
-  if len(strings) > 0 {
-    sort.Strings(strings)
-    fmt.Printf("string  %s\n", strings)
-  }
-  if df.shared.Num() > 0 {
-    list := df.shared.NameList()
-    sort.Strings(list)
-    fmt.Printf("viewed  %s\n", list)
-  }
-  if df.sharedMaps {
-    fmt.Printf("shared  YES (structure ID: %d)\n", df.structureUID)
-  }
-  fmt.Printf("rows    %d\n", len(df.indices))
-  fmt.Printf("max CPU %d\n", df.ActualMaxCPU())
-  if df.indexViewed {
-    fmt.Printf("row-wise view: %d / %d rows\n", len(df.indices), len(df.mask))
-  }
+  df.RenderSummary(os.Stdout)
   return df
 }
 
-// PrintHead prints the first n rows of the dataframe.
+// PrintHead prints the first n rows of the dataframe, one line per column.
 // If n is negative or if n is greater than the number of rows, it will print
 // all the rows.
 // floatFormat describes how you want floats to be printed, e.g. %.4f
@@ -63,6 +28,8 @@ func (df *DataFrame) PrintSummary() *DataFrame {
 // Everything is printed on stdout. Nothing on stderr.
 // PrintHead returns the dataframe itself so you can write
 //  df.PrintSummary().PrintHead(n, "") or df.PrintHead(n, "").PrintSummary()
+// Use RenderHead to print the rows as a table, with more options and other
+// output formats.
 func (df *DataFrame) PrintHead(n int, floatFormat string) *DataFrame {
   if n < 0 || n > len(df.indices) {
     n = df.NumRows()
@@ -129,60 +96,25 @@ func (df *DataFrame) PrintHead(n int, floatFormat string) *DataFrame {
 // shorthands maps column names to shorter column names in order to avoid
 // cluttering the output. You can leave it empty, nil, or call GoodShortNames()
 // to get optimally small truncated names.
+// Use RenderRecords to write the records elsewhere.
 func (df *DataFrame) PrintRecords(n int, floatFormat string, shorthands map[string]string) *DataFrame {
-  if n < 0 || n > len(df.indices) {
-    n = df.NumRows()
-  }
-  if len(floatFormat) == 0 {
-    floatFormat = " %.3f"
-  } else {
-    floatFormat = " " + floatFormat
-  }
-  order := df.Header().NameList()
-  sort.Strings(order)
-  indexFormat := fmt.Sprintf("[%s:%%d]", intFormat(n-1))
-  for i, j := range df.indices {
-    if i >= n {
-      break
-    }
-    fmt.Printf(indexFormat, i, j)
-    for _, colName := range order {
-      // print the column name
-      fmt.Printf(" ")
-      if shorthand, defined := shorthands[colName]; defined {
-        fmt.Printf(shorthand)
-      } else {
-        fmt.Printf(colName)
-      }
-      fmt.Printf(":")
-      // print the value
-      if val, ok := df.floats[colName]; ok {
-        fmt.Printf(floatFormat, val[j])
-      } else if val, ok := df.objects[colName]; ok {
-        df.printObject(val[j], df.stringHeader.contains(colName))
-      } else if val, ok := df.ints[colName]; ok {
-        fmt.Printf(" %d", val[j])
-      } else if val, ok := df.bools[colName]; ok {
-        printBool(val[j])
-      } else {
-        fmt.Printf(" @ERROR@")
-      }
-    }
-    fmt.Println("")
-  }
+  df.RenderRecords(os.Stdout, n, RenderOptions{FloatFormat: floatFormat, ShortNames: shorthands})
   return df
 }
 
-// GoodShortNames returns short versions of column names for PrintRecords().
+// GoodShortNames returns short versions of column names for PrintRecords()
+// and RenderOptions.ShortNames.
 // minLength is the minimum length of shortened names.
 // If minLength is zero or negative, it will default to minLength=3.
-// This function is not deterministic.
+// Columns are shortened in alphabetical order, so the result is deterministic.
 func (df *DataFrame) GoodShortNames(minLength int) map[string]string {
   if minLength <= 0 {
     minLength = 3
   }
   invResult := make(map[string]string)
-  for col := range df.Header().NameSet() {
+  cols := df.Header().NameList()
+  sort.Strings(cols)
+  for _, col := range cols {
     for length := minLength; length <= len(col); length++ {
       candidate := col[:length]
       if _, existing := invResult[candidate]; !existing {
@@ -35,6 +35,10 @@ type RawData struct {
   dataUID uint64
   // whether an object is a string or not
   stringHeader ColumnHeader
+  // columns whose data is read-only, see DataFrame.Freeze()
+  frozen ColumnHeader
+  // whether the maps cannot be altered in-place
+  frozenMaps bool
   // later we'll switch to:
   // special types of []interface{}. strings: 1
   // objectColTypes map[string]ObjectType
@@ -176,6 +180,7 @@ func (data *RawData) ActualMaxCPU() int {
 // Unshare is the in-place, low-level version of DataFrame.DetachView().
 // For your own sake, please use DataFrame.DetachView() instead.
 func (data *RawData) Unshare(columns...string) {
+  data.checkMutableMaps()
   // deep-copy the columns that are viewed
   if !data.sharedMaps && data.shared.Num() == 0 {
     return
@@ -222,6 +227,7 @@ func (data *RawData) Unshare(columns...string) {
   for col := range colSet {
     data.shared.remove(col)
   }
+  data.thawColumns(data.Header().Except(data.shared.NameList()...).NameList()...)
 }
 
 // Drop removes the given columns.
@@ -229,6 +235,11 @@ func (data *RawData) Drop(columns ...string) {
   if len(columns) == 0 {
     return
   }
+  data.checkMutableMaps()
+  if data.sharedMaps {
+    data.reallocateMaps()  // don't drop the columns of the parent dataframe
+  }
+  data.thawColumns(columns...)
   for _, col := range columns {
     data.shared.remove(col)
     data.stringHeader.remove(col)
@@ -245,6 +256,7 @@ func (data *RawData) Drop(columns ...string) {
 //  df.View().Rename("apples", "oranges").Ints("oranges").Set(0, 42)
 // It will change df's number of apples to 42 at index=0.
 func (data *RawData) Rename(oldName string, newName string) {
+  data.checkMutableMaps()
   if data.sharedMaps {
     data.reallocateMaps()
   }
@@ -271,10 +283,20 @@ func (data *RawData) Rename(oldName string, newName string) {
     data.shared.remove(oldName)
     data.shared.add(newName)
   }
+  if data.frozen.contains(oldName) {
+    data.thawColumns(oldName)
+    data.freezeColumns(newName)
+  } else {
+    data.thawColumns(newName)
+  }
 }
 
 // AllocInts allocates new empty integer columns.
 func (data *RawData) AllocInts(columns ...string) {
+  if len(columns) > 0 {
+    data.checkMutableMaps()
+    data.thawColumns(columns...)
+  }
   if data.sharedMaps && len(columns) > 0 {
     data.reallocateMaps()
   }
@@ -286,6 +308,10 @@ func (data *RawData) AllocInts(columns ...string) {
 
 // AllocFloats allocates new empty float columns.
 func (data *RawData) AllocFloats(columns ...string) {
+  if len(columns) > 0 {
+    data.checkMutableMaps()
+    data.thawColumns(columns...)
+  }
   if data.sharedMaps && len(columns) > 0 {
     data.reallocateMaps()
   }
@@ -297,6 +323,10 @@ func (data *RawData) AllocFloats(columns ...string) {
 
 // AllocBools allocates new empty float columns.
 func (data *RawData) AllocBools(columns ...string) {
+  if len(columns) > 0 {
+    data.checkMutableMaps()
+    data.thawColumns(columns...)
+  }
   if data.sharedMaps && len(columns) > 0 {
     data.reallocateMaps()
   }
@@ -308,6 +338,10 @@ func (data *RawData) AllocBools(columns ...string) {
 
 // AllocObjects allocates new empty object columns.
 func (data *RawData) AllocObjects(columns ...string) {
+  if len(columns) > 0 {
+    data.checkMutableMaps()
+    data.thawColumns(columns...)
+  }
   if data.sharedMaps && len(columns) > 0 {
     data.reallocateMaps()
   }
@@ -334,6 +368,7 @@ func (data *RawData) AllocStrings(columns ...string) {
 // expect this function to exlusively transfer viewed data when it's called on a
 // dataframe.
 func (data *RawData) TransferRawDataFrom(from *RawData) {
+  data.checkMutableMaps()
   if data.sharedMaps {
     data.reallocateMaps()
   }
@@ -361,6 +396,10 @@ func (data *RawData) TransferRawDataFrom(from *RawData) {
   for col := range from.stringHeader.get() {
     data.stringHeader.add(col)
   }
+  data.thawColumns(from.Header().Except(from.frozen.NameList()...).NameList()...)
+  if from.frozen.Num() > 0 {
+    data.freezeColumns(from.frozen.NameList()...)
+  }
 }
 
 // MergeRawDataColumns transfers data from multiple RawData structures.
@@ -429,6 +468,8 @@ func MergeRawDataRows(list []*RawData) *RawData {
 // convert only parts of dataframe's column, given that mixed types are not
 // allowed for numerical columns.
 func (data *RawData) IntToFloats(columns...string) {
+  data.checkMutableMaps()
+  data.thawColumns(columns...)
   if data.sharedMaps {
     data.reallocateMaps()
   }
@@ -448,6 +489,8 @@ func (data *RawData) IntToFloats(columns...string) {
 // convert only parts of dataframe's column, given that mixed types are not
 // allowed for numerical columns.
 func (data *RawData) BoolToFloats(columns...string) {
+  data.checkMutableMaps()
+  data.thawColumns(columns...)
   if data.sharedMaps {
     data.reallocateMaps()
   }
@@ -482,6 +525,7 @@ func (data *RawData) reallocateMaps() {
   data.floats = tmp.floats
   data.objects = tmp.objects
   data.shared = tmp.shared  // all columns
+  data.stringHeader = tmp.stringHeader  // copy, so that views can drop strings
   data.sharedMaps = false
 }
 
@@ -24,3 +24,30 @@ func TestDrop(t *testing.T) {
   header := df.StringHeader().NameList()
   u.AssertIntEquals("num strings", len(header), 0, t)
 }
+
+func TestReallocateMapsHeader(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddStrings("strings", "a", "b")
+  df := builder.ToDataFrame()
+
+  view := df.View()
+  view.reallocateMaps()
+  view.Drop("strings")
+  u.AssertIntEquals("view", view.StringHeader().Num(), 0, t)
+  u.AssertIntEquals("parent", df.StringHeader().Num(), 1, t)
+}
+
+func TestDropFromView(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddInts("ints", 0, 1)
+  builder.AddStrings("strings", "a", "b")
+  df := builder.ToDataFrame()
+
+  view := df.View()
+  view.Drop("ints", "strings")
+  u.AssertIntEquals("view", view.NumColumns(), 0, t)
+  // the parent keeps its columns
+  u.AssertIntEquals("parent", df.NumColumns(), 2, t)
+  u.AssertIntEquals("ints", df.Ints("ints").Get(1), 1, t)
+  u.AssertStringEquals("strings", df.Strings("strings").Get(1), "b", t)
+}
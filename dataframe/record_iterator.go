@@ -0,0 +1,235 @@
+package dataframe
+
+import (
+  "fmt"
+  "math"
+)
+
+// Row is a reusable row of typed values delivered by RecordIterator.
+// Values are accessed by their position in the list of columns passed to
+// NewRecordIterator. Use Row.Index to find the position of a column.
+type Row struct {
+  kinds     []fieldKind
+  positions map[string]int
+  floats    []float64
+  ints      []int
+  bools     []bool
+  objects   []interface{}
+}
+
+// RecordIterator is a structure to iterate over a dataframe one row at a time.
+// Unlike Float32Iterator and Float64Iterator, it can iterate through every
+// kind of column, including string and object columns.
+// Rows are delivered either as slices of interface{} or as Row structures.
+type RecordIterator struct {
+  RowIterator
+  oColumns  []int
+  row       Row
+  record    []interface{}
+}
+
+// NewRecordIterator allocates a new row iterator to allow you to iterate
+// over columns of any type.
+// It will panic if a given column doesn't exist.
+// Row elements will be delivered in the same order as the columns passed as
+// argument.
+func NewRecordIterator(df *DataFrame, columns []string) *RecordIterator {
+  ite := new(RecordIterator)
+  ite.columns = columns
+  ite.initialized = true
+  ite.row.kinds = make([]fieldKind, len(columns))
+  ite.row.positions = make(map[string]int)
+  for k, col := range columns {
+    ite.row.positions[col] = k
+    if _, ok := df.floats[col]; ok {
+      ite.fColumns = append(ite.fColumns, k)
+      ite.row.kinds[k] = floatField
+    } else if _, ok := df.ints[col]; ok {
+      ite.iColumns = append(ite.iColumns, k)
+      ite.row.kinds[k] = intField
+    } else if _, ok := df.bools[col]; ok {
+      ite.bColumns = append(ite.bColumns, k)
+      ite.row.kinds[k] = boolField
+    } else if _, ok := df.objects[col]; ok {
+      ite.oColumns = append(ite.oColumns, k)
+      if df.stringHeader.contains(col) {
+        ite.row.kinds[k] = stringField
+      } else {
+        ite.row.kinds[k] = objectField
+      }
+    } else {
+      panic(fmt.Sprintf("column %s does not exist", col))
+    }
+  }
+  ite.df = df
+  // pre-allocation of the data
+  ite.row.floats = make([]float64, len(columns))
+  ite.row.ints = make([]int, len(columns))
+  ite.row.bools = make([]bool, len(columns))
+  ite.row.objects = make([]interface{}, len(columns))
+  ite.record = make([]interface{}, len(columns))
+
+  return ite
+}
+
+// Reset recycles the iterator's pre-allocated data for another dataframe with
+// the same columns.
+// If check is true, it will be verified that the columns are the same,
+// including object columns.
+func (ite *RecordIterator) Reset(df *DataFrame, check bool) {
+  if check {
+    for _, col := range ite.oColumns {
+      colName := ite.columns[col]
+      if _, ok := df.objects[colName]; !ok {
+        panic(fmt.Sprintf("%s missing from object columns", colName))
+      }
+    }
+  }
+  ite.RowIterator.Reset(df, check)
+}
+
+// next returns the index of the next row in the original data, or -1 if there
+// is no more row.
+func (ite *RecordIterator) next() int {
+  if ite.dfIndex == len(ite.df.indices) {
+    return -1
+  }
+  idx := ite.df.indices[ite.dfIndex]
+  ite.dfIndex++
+  return idx
+}
+
+// NextRow fills the iterator's Row with the values of the next row and returns
+// it, alongside its index in the view and its index in the original data.
+// If there is no more row, it returns nil, the size of the view and the size of
+// the original data.
+// NextRow recycles the Row structure, so you shouldn't store it.
+func (ite *RecordIterator) NextRow() (*Row, int, int) {
+  idx := ite.next()
+  if idx < 0 {
+    return nil, len(ite.df.indices), ite.df.NumAllocatedRows()
+  }
+  for _, k := range ite.fColumns {
+    ite.row.floats[k] = ite.df.floats[ite.columns[k]][idx]
+  }
+  for _, k := range ite.iColumns {
+    ite.row.ints[k] = ite.df.ints[ite.columns[k]][idx]
+  }
+  for _, k := range ite.bColumns {
+    ite.row.bools[k] = ite.df.bools[ite.columns[k]][idx]
+  }
+  for _, k := range ite.oColumns {
+    ite.row.objects[k] = ite.df.objects[ite.columns[k]][idx]
+  }
+  return &ite.row, ite.dfIndex-1, idx
+}
+
+// NextRecord returns the values of the next row packed into a slice of
+// interfaces, alongside its index in the view and its index in the original
+// data.
+// If there is no more row, it returns nil, the size of the view and the size of
+// the original data.
+// You can safely change the values of the slice, but NextRecord recycles the
+// slice, so you shouldn't store it.
+func (ite *RecordIterator) NextRecord() ([]interface{}, int, int) {
+  idx := ite.next()
+  if idx < 0 {
+    return nil, len(ite.df.indices), ite.df.NumAllocatedRows()
+  }
+  for _, k := range ite.fColumns {
+    ite.record[k] = ite.df.floats[ite.columns[k]][idx]
+  }
+  for _, k := range ite.iColumns {
+    ite.record[k] = ite.df.ints[ite.columns[k]][idx]
+  }
+  for _, k := range ite.bColumns {
+    ite.record[k] = ite.df.bools[ite.columns[k]][idx]
+  }
+  for _, k := range ite.oColumns {
+    ite.record[k] = ite.df.objects[ite.columns[k]][idx]
+  }
+  return ite.record, ite.dfIndex-1, idx
+}
+
+// Index returns the position of the given column in the row, or -1 if the
+// column was not passed to NewRecordIterator.
+func (row *Row) Index(colName string) int {
+  if k, ok := row.positions[colName]; ok {
+    return k
+  }
+  return -1
+}
+
+// Float returns the k-th value of the row as a float.
+// Integers are converted to floats, and booleans are converted to 0 and 1.
+// It will panic if the k-th column is an object column.
+func (row *Row) Float(k int) float64 {
+  switch row.kinds[k] {
+  case floatField:
+    return row.floats[k]
+  case intField:
+    return float64(row.ints[k])
+  case boolField:
+    if row.bools[k] {
+      return 1
+    }
+    return 0
+  }
+  panic(fmt.Sprintf("value %d is not a float, an int or a bool", k))
+}
+
+// Int returns the k-th value of the row.
+// It will panic if the k-th column is not an int column.
+func (row *Row) Int(k int) int {
+  if row.kinds[k] != intField {
+    panic(fmt.Sprintf("value %d is not an int", k))
+  }
+  return row.ints[k]
+}
+
+// Bool returns the k-th value of the row.
+// It will panic if the k-th column is not a bool column.
+func (row *Row) Bool(k int) bool {
+  if row.kinds[k] != boolField {
+    panic(fmt.Sprintf("value %d is not a bool", k))
+  }
+  return row.bools[k]
+}
+
+// String returns the k-th value of the row.
+// It will panic if the k-th column is not a string column or if the value is
+// missing. Call IsMissing first if the column may contain missing values.
+func (row *Row) String(k int) string {
+  if row.kinds[k] != stringField {
+    panic(fmt.Sprintf("value %d is not a string", k))
+  }
+  return row.objects[k].(string)
+}
+
+// Object returns the k-th value of the row packed into an interface, whatever
+// the type of the column.
+func (row *Row) Object(k int) interface{} {
+  switch row.kinds[k] {
+  case floatField:
+    return row.floats[k]
+  case intField:
+    return row.ints[k]
+  case boolField:
+    return row.bools[k]
+  }
+  return row.objects[k]
+}
+
+// IsMissing returns whether the k-th value of the row is missing, i.e. NaN for
+// floats, -1 for ints and nil for objects. Booleans are never missing.
+func (row *Row) IsMissing(k int) bool {
+  switch row.kinds[k] {
+  case floatField:
+    return math.IsNaN(row.floats[k])
+  case intField:
+    return row.ints[k] == -1
+  case boolField:
+    return false
+  }
+  return row.objects[k] == nil
+}
@@ -0,0 +1,305 @@
+package dataframe
+
+import (
+  "fmt"
+  "html"
+  "io"
+  "sort"
+  "strings"
+  "unicode/utf8"
+)
+
+type RenderFormat int
+
+const (
+  // Columns aligned with spaces.
+  PlainTextFormat RenderFormat = iota
+  // GitHub-flavored Markdown table.
+  MarkdownFormat
+  // HTML <table> element.
+  HTMLFormat
+)
+
+type RenderOptions struct {
+  Format RenderFormat
+
+  // How floats are formatted, e.g. %.4f or %g. Default: %.3f
+  FloatFormat string
+
+  // Float formats of specific columns, taking precedence over FloatFormat.
+  ColumnFloatFormats map[string]string
+
+  // Maximum number of characters of the column names and the string and
+  // object values. Longer names and values are truncated and end with "…".
+  // Numbers are never truncated.
+  // Zero or negative values mean no truncation.
+  MaxWidth int
+
+  // Maps column names to the names displayed in the header, e.g. the output
+  // of GoodShortNames(). Columns that aren't in the map keep their name.
+  ShortNames map[string]string
+
+  // Columns to render, in this order. Default: all the columns, sorted by
+  // name.
+  Columns []string
+}
+
+// renderColumns returns the columns to render, or an error if one of the
+// requested columns doesn't exist.
+func (df *DataFrame) renderColumns(opts RenderOptions) ([]string, error) {
+  if len(opts.Columns) == 0 {
+    cols := df.Header().NameList()
+    sort.Strings(cols)
+    return cols, nil
+  }
+  for _, col := range opts.Columns {
+    if len(df.columnKind(col)) == 0 {
+      return nil, fmt.Errorf("column %s doesn't exist", col)
+    }
+  }
+  return opts.Columns, nil
+}
+
+// renderName returns the displayed name of the column.
+func renderName(col string, opts RenderOptions) string {
+  if shorthand, ok := opts.ShortNames[col]; ok {
+    col = shorthand
+  }
+  return truncateCell(col, opts.MaxWidth)
+}
+
+// truncateCell shortens the string to maxWidth characters.
+func truncateCell(s string, maxWidth int) string {
+  if maxWidth <= 0 || utf8.RuneCountInString(s) <= maxWidth {
+    return s
+  }
+  runes := []rune(s)
+  return string(runes[:maxWidth-1]) + "…"
+}
+
+// renderCell formats the value of the given column at the given row of the
+// view.
+func (df *DataFrame) renderCell(col string, i int, quote bool, opts RenderOptions) string {
+  j := df.indices[i]
+  var result string
+  if vals, ok := df.floats[col]; ok {
+    format, ok := opts.ColumnFloatFormats[col]
+    if !ok {
+      format = opts.FloatFormat
+    }
+    if len(format) == 0 {
+      format = "%.3f"
+    }
+    result = fmt.Sprintf(format, vals[j])
+  } else if vals, ok := df.ints[col]; ok {
+    result = fmt.Sprintf("%d", vals[j])
+  } else if vals, ok := df.bools[col]; ok {
+    if vals[j] {
+      result = "1"
+    } else {
+      result = "0"
+    }
+  } else if vals, ok := df.objects[col]; ok {
+    v := vals[j]
+    if v == nil {
+      result = "<missing>"
+    } else if !df.stringHeader.contains(col) {
+      result = fmt.Sprintf("%v", v)
+    } else if df.textEncoding != nil {
+      result = "<NOT-UTF8>"
+    } else if quote {
+      result = "'" + v.(string) + "'"
+    } else {
+      result = v.(string)
+    }
+    result = truncateCell(result, opts.MaxWidth)
+  } else {
+    result = "@ERROR@"
+  }
+  return result
+}
+
+// isRightAligned returns true for the columns of numbers.
+func (df *DataFrame) isRightAligned(col string) bool {
+  kind := df.columnKind(col)
+  return kind == FloatKind || kind == IntKind || kind == BoolKind
+}
+
+// RenderHead writes the first n rows of the dataframe into w, as a table
+// with one row per line and one column per field, in the format given by
+// opts.Format.
+// If n is negative or if n is greater than the number of rows, it will render
+// all the rows.
+// It returns an error if one of opts.Columns doesn't exist or if the writer
+// fails.
+func (df *DataFrame) RenderHead(w io.Writer, n int, opts RenderOptions) error {
+  if n < 0 || n > len(df.indices) {
+    n = df.NumRows()
+  }
+  cols, err := df.renderColumns(opts)
+  if err != nil {
+    return err
+  }
+  header := make([]string, len(cols))
+  for k, col := range cols {
+    header[k] = renderName(col, opts)
+  }
+  rows := make([][]string, n)
+  for i := range rows {
+    rows[i] = make([]string, len(cols))
+    for k, col := range cols {
+      rows[i][k] = df.renderCell(col, i, false, opts)
+    }
+  }
+  var sb strings.Builder
+  switch opts.Format {
+  case MarkdownFormat:
+    df.renderMarkdown(&sb, cols, header, rows)
+  case HTMLFormat:
+    renderHTML(&sb, header, rows)
+  default:
+    df.renderPlainText(&sb, cols, header, rows)
+  }
+  _, err = io.WriteString(w, sb.String())
+  return err
+}
+
+func (df *DataFrame) renderPlainText(sb *strings.Builder, cols []string, header []string, rows [][]string) {
+  widths := make([]int, len(cols))
+  for k := range cols {
+    widths[k] = utf8.RuneCountInString(header[k])
+    for _, row := range rows {
+      if width := utf8.RuneCountInString(row[k]); width > widths[k] {
+        widths[k] = width
+      }
+    }
+  }
+  writeLine := func(cells []string) {
+    for k, cell := range cells {
+      if k > 0 {
+        sb.WriteString("  ")
+      }
+      padding := strings.Repeat(" ", widths[k] - utf8.RuneCountInString(cell))
+      if df.isRightAligned(cols[k]) {
+        sb.WriteString(padding)
+        sb.WriteString(cell)
+      } else if k < len(cells) - 1 {
+        sb.WriteString(cell)
+        sb.WriteString(padding)
+      } else {
+        sb.WriteString(cell)  // no trailing spaces
+      }
+    }
+    sb.WriteString("\n")
+  }
+  writeLine(header)
+  for _, row := range rows {
+    writeLine(row)
+  }
+}
+
+func (df *DataFrame) renderMarkdown(sb *strings.Builder, cols []string, header []string, rows [][]string) {
+  escape := strings.NewReplacer("|", "\\|", "\n", " ")
+  writeLine := func(cells []string) {
+    sb.WriteString("|")
+    for _, cell := range cells {
+      sb.WriteString(" ")
+      sb.WriteString(escape.Replace(cell))
+      sb.WriteString(" |")
+    }
+    sb.WriteString("\n")
+  }
+  writeLine(header)
+  sb.WriteString("|")
+  for _, col := range cols {
+    if df.isRightAligned(col) {
+      sb.WriteString(" ---: |")
+    } else {
+      sb.WriteString(" --- |")
+    }
+  }
+  sb.WriteString("\n")
+  for _, row := range rows {
+    writeLine(row)
+  }
+}
+
+func renderHTML(sb *strings.Builder, header []string, rows [][]string) {
+  writeLine := func(cells []string, tag string) {
+    sb.WriteString("<tr>")
+    for _, cell := range cells {
+      sb.WriteString("<" + tag + ">")
+      sb.WriteString(html.EscapeString(cell))
+      sb.WriteString("</" + tag + ">")
+    }
+    sb.WriteString("</tr>\n")
+  }
+  sb.WriteString("<table>\n<thead>\n")
+  writeLine(header, "th")
+  sb.WriteString("</thead>\n<tbody>\n")
+  for _, row := range rows {
+    writeLine(row, "td")
+  }
+  sb.WriteString("</tbody>\n</table>\n")
+}
+
+// RenderRecords writes the first n rows of the dataframe into w, one line per
+// row, each value preceded by the name of its column.
+// Every line starts with the position of the row in the view and its position
+// in the underlying data.
+// opts.Format is ignored since records are always rendered as plain text.
+// It returns an error if one of opts.Columns doesn't exist or if the writer
+// fails.
+func (df *DataFrame) RenderRecords(w io.Writer, n int, opts RenderOptions) error {
+  if n < 0 || n > len(df.indices) {
+    n = df.NumRows()
+  }
+  cols, err := df.renderColumns(opts)
+  if err != nil {
+    return err
+  }
+  var sb strings.Builder
+  indexFormat := fmt.Sprintf("[%s:%%d]", intFormat(n-1))
+  for i := 0; i < n; i++ {
+    sb.WriteString(fmt.Sprintf(indexFormat, i, df.indices[i]))
+    for _, col := range cols {
+      sb.WriteString(" ")
+      sb.WriteString(renderName(col, opts))
+      sb.WriteString(": ")
+      sb.WriteString(df.renderCell(col, i, true, opts))
+    }
+    sb.WriteString("\n")
+  }
+  _, err = io.WriteString(w, sb.String())
+  return err
+}
+
+// RenderSummary writes information about the content of the dataframe into w,
+// such as the name of the columns and the number of rows.
+// It doesn't write the data.
+// It returns an error if the writer fails.
+func (df *DataFrame) RenderSummary(w io.Writer) error {
+  var sb strings.Builder
+  writeCols := func(title string, cols []string) {
+    if len(cols) > 0 {
+      sort.Strings(cols)
+      sb.WriteString(fmt.Sprintf("%-8s%s\n", title, cols))
+    }
+  }
+  writeCols("float", df.FloatHeader().NameList())
+  writeCols("int", df.IntHeader().NameList())
+  writeCols("bool", df.BoolHeader().NameList())
+  writeCols("object", df.ObjectHeader().ExceptHeader(df.StringHeader()).NameList())
+  writeCols("string", df.StringHeader().NameList())
+  writeCols("viewed", df.shared.NameList())
+  if df.sharedMaps {
+    sb.WriteString(fmt.Sprintf("shared  YES (structure ID: %d)\n", df.structureUID))
+  }
+  sb.WriteString(fmt.Sprintf("rows    %d\n", len(df.indices)))
+  sb.WriteString(fmt.Sprintf("max CPU %d\n", df.ActualMaxCPU()))
+  if df.indexViewed {
+    sb.WriteString(fmt.Sprintf("row-wise view: %d / %d rows\n", len(df.indices), len(df.mask)))
+  }
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
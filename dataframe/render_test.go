@@ -0,0 +1,71 @@
+package dataframe
+
+import (
+  "bytes"
+  "testing"
+  u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func renderTestData() *DataFrame {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("height", 170, 180.25, 165)
+  builder.AddStrings("name", "Karen", "John|Doe", "<b>")
+  builder.AddInts("age", 25, 3, 120)
+  return builder.ToDataFrame()
+}
+
+func TestRenderPlainText(t *testing.T) {
+  df := renderTestData()
+  var buf bytes.Buffer
+  u.AssertNoError(df.RenderHead(&buf, 2, RenderOptions{}), t)
+  expected := "age   height  name\n" +
+              " 25  170.000  Karen\n" +
+              "  3  180.250  John|Doe\n"
+  u.AssertStringEquals("plain", buf.String(), expected, t)
+
+  buf.Reset()
+  opts := RenderOptions{
+    MaxWidth: 4,
+    ColumnFloatFormats: map[string]string{"height": "%.1f"},
+    ShortNames: df.GoodShortNames(1),
+    Columns: []string{"name", "height"},
+  }
+  u.AssertNoError(df.RenderHead(&buf, -1, opts), t)
+  expected = "n         h\n" +
+              "Kar…  170.0\n" +
+              "Joh…  180.2\n" +
+              "<b>   165.0\n"
+  u.AssertStringEquals("options", buf.String(), expected, t)
+
+  opts.Columns = []string{"weight"}
+  u.AssertTrue("unknown column", df.RenderHead(&buf, -1, opts) != nil, t)
+}
+
+func TestRenderMarkdownAndHTML(t *testing.T) {
+  df := renderTestData().ColumnView("name", "age")
+  var buf bytes.Buffer
+  u.AssertNoError(df.RenderHead(&buf, 2, RenderOptions{Format: MarkdownFormat}), t)
+  expected := "| age | name |\n" +
+              "| ---: | --- |\n" +
+              "| 25 | Karen |\n" +
+              "| 3 | John\\|Doe |\n"
+  u.AssertStringEquals("markdown", buf.String(), expected, t)
+
+  buf.Reset()
+  u.AssertNoError(df.RenderHead(&buf, 3, RenderOptions{Format: HTMLFormat, Columns: []string{"name"}}), t)
+  expected = "<table>\n<thead>\n<tr><th>name</th></tr>\n</thead>\n<tbody>\n" +
+             "<tr><td>Karen</td></tr>\n<tr><td>John|Doe</td></tr>\n<tr><td>&lt;b&gt;</td></tr>\n" +
+             "</tbody>\n</table>\n"
+  u.AssertStringEquals("html", buf.String(), expected, t)
+}
+
+func TestRenderRecords(t *testing.T) {
+  df := renderTestData().ColumnView("name", "age")
+  var buf bytes.Buffer
+  u.AssertNoError(df.RenderRecords(&buf, 1, RenderOptions{}), t)
+  u.AssertStringEquals("records", buf.String(), "[0:0] age: 25 name: 'Karen'\n", t)
+
+  buf.Reset()
+  u.AssertNoError(df.RenderSummary(&buf), t)
+  u.AssertTrue("summary", bytes.Contains(buf.Bytes(), []byte("rows    3\n")), t)
+}
@@ -129,3 +129,74 @@ func (ite *Float32Iterator) NextRow() ([]float32, int, int) {
 
   return row, ite.dfIndex-1, idx
 }
+
+// Float64Iterator is a structure to iterate over a dataframe one row at a time.
+// The rows provided to the user will be slices of float64.
+// Float64Iterator cannot iterate through object columns.
+// The delivered rows can be safely changed with no effect on the dataframe.
+type Float64Iterator struct {
+  RowIterator
+  rows      [128][]float64
+}
+
+// NewFloat64Iterator allocates a new row iterator to allow you to iterate
+// over float, bool and int columns as float64.
+// It will panic if a given column is an object column or doesn't exist.
+// Row elements will be delivered in the same order as the columns passed as
+// argument.
+func NewFloat64Iterator(df *DataFrame, columns []string) *Float64Iterator {
+  ite := new(Float64Iterator)
+  ite.initialize(df, columns)
+  ite.df = df
+  // pre-allocation of the data
+  for i := range ite.rows {
+    ite.rows[i] = make([]float64, len(ite.columns))
+  }
+  return ite
+}
+
+// NextRow returns a single row, its index in the view and its index in the
+// original data. If there is no more row, it returns nil, the size of the view
+// and the size of the original data.
+// You can safely change the values of the row since they are copies of the
+// original data. However, NextRow recycles the float slice, so you shouldn't
+// store the slice.
+func (ite *Float64Iterator) NextRow() ([]float64, int, int) {
+  if ite.dfIndex == len(ite.df.indices) {
+    return nil, len(ite.df.indices), ite.df.NumAllocatedRows()
+  }
+  if ite.rowOffset == 0 {
+    indices := ite.RowIterator.nextIndices()
+    // bools
+    for _, colIx := range ite.bColumns {
+      vals := ite.df.bools[ite.columns[colIx]]
+      for j, i := range indices {
+        if vals[i] {
+          ite.rows[j][colIx] = 1.0
+        } else {
+          ite.rows[j][colIx] = 0
+        }
+      }
+    }
+    // ints
+    for _, colIx := range ite.iColumns {
+      vals := ite.df.ints[ite.columns[colIx]]
+      for j, i := range indices {
+        ite.rows[j][colIx] = float64(vals[i])
+      }
+    }
+    // float64
+    for _, colIx := range ite.fColumns {
+      vals := ite.df.floats[ite.columns[colIx]]
+      for j, i := range indices {
+        ite.rows[j][colIx] = vals[i]
+      }
+    }
+  }
+  row := ite.rows[ite.rowOffset]
+  idx := ite.subInds[ite.rowOffset]
+  ite.rowOffset = (ite.rowOffset + 1) % len(ite.rows)
+  ite.dfIndex++
+
+  return row, ite.dfIndex-1, idx
+}
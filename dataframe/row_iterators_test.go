@@ -23,3 +23,66 @@ func Test1Float32Iterator(t *testing.T) {
   }
   u.AssertIntEquals("num iterations", i, len(ints), t)
 }
+
+func TestFloat64Iterator(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  ints := u.MakeRange(0, 300, 1)
+  builder.AddInts("ints1", ints...)
+  builder.AddFloats("floats1", make([]float64, len(ints))...)
+  builder.AddBools("bools1", make([]bool, len(ints))...)
+  df := builder.ToDataFrame()
+
+  iterator := NewFloat64Iterator(df, []string{"floats1", "bools1", "ints1"})
+  i := 0
+  for row, j, _ := iterator.NextRow(); row != nil; row, j, _ = iterator.NextRow() {
+    u.AssertIntEquals("row number", i, j, t)
+    u.AssertIntEquals("data", int(row[2]), i, t)
+    i++
+  }
+  u.AssertIntEquals("num iterations", i, len(ints), t)
+
+  // recycle the iterator
+  iterator.Reset(df.SliceView(150, 300), true)
+  i = 0
+  for row, _, _ := iterator.NextRow(); row != nil; row, _, _ = iterator.NextRow() {
+    u.AssertIntEquals("data", int(row[2]), 150 + i, t)
+    i++
+  }
+  u.AssertIntEquals("num iterations", i, 150, t)
+}
+
+func TestRecordIterator(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddInts("ints", 1, -1, 3)
+  builder.AddFloats("floats", 0.5, 1.5, 2.5)
+  builder.AddBools("bools", true, false, true)
+  builder.AddObjects("strings", "a", nil, "c").MarkAsString("strings")
+  df := builder.ToDataFrame().ReverseView()
+
+  columns := []string{"strings", "ints", "floats", "bools"}
+  iterator := NewRecordIterator(df, columns)
+  row, j, k := iterator.NextRow()
+  u.AssertIntEquals("view index", j, 0, t)
+  u.AssertIntEquals("raw index", k, 2, t)
+  u.AssertStringEquals("string", row.String(row.Index("strings")), "c", t)
+  u.AssertIntEquals("int", row.Int(1), 3, t)
+  u.AssertFloatEquals("float", row.Float(2), 2.5, t)
+  u.AssertFloatEquals("bool as float", row.Float(3), 1, t)
+  u.AssertTrue("bool", row.Bool(3), t)
+  u.AssertIntEquals("unknown column", row.Index("unknown"), -1, t)
+
+  record, _, _ := iterator.NextRecord()
+  u.AssertTrue("missing string", record[0] == nil, t)
+  u.AssertIntEquals("missing int", record[1].(int), -1, t)
+  row, _, _ = iterator.NextRow()
+  u.AssertStringEquals("string", row.Object(0).(string), "a", t)
+  row, _, _ = iterator.NextRow()
+  u.AssertTrue("end", row == nil, t)
+
+  // recycle the iterator
+  iterator.Reset(df.SliceView(1, 2), true)
+  row, _, _ = iterator.NextRow()
+  u.AssertTrue("missing string", row.IsMissing(0), t)
+  u.AssertTrue("missing int", row.IsMissing(1), t)
+  u.AssertFalse("non-missing float", row.IsMissing(2), t)
+}
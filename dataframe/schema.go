@@ -0,0 +1,520 @@
+package dataframe
+
+import (
+  "fmt"
+  "math"
+  "sort"
+  "strconv"
+  "strings"
+  "github.com/rom1mouret/ml-essentials/utils"
+)
+
+// Column kinds as stored in ColumnSchema.Kind
+const(
+  FloatKind  = "float"
+  IntKind    = "int"
+  BoolKind   = "bool"
+  StringKind = "string"
+  ObjectKind = "object"
+)
+
+// ColumnSchema describes a column of the training data.
+type ColumnSchema struct {
+  Name       string
+  // One of "float", "int", "bool", "string" or "object".
+  Kind       string
+  // Whether the training data contains missing values.
+  HasMissing bool
+  // Range of the non-missing values of float and int columns.
+  // nil if the column has no value or is not a numerical column.
+  Min        *float64 `json:",omitempty"`
+  Max        *float64 `json:",omitempty"`
+  // Sorted set of values of string columns, unless there are more than
+  // SchemaOptions.MaxCategories distinct values.
+  Categories []string `json:",omitempty"`
+}
+
+// Schema is a json-serializable description of the columns of a dataframe.
+// It is meant to be captured from the training data and enforced on the data
+// given to the model at inference time with Conform.
+type Schema struct {
+  Columns []ColumnSchema
+}
+
+// SchemaOptions specifies what CaptureSchema records.
+type SchemaOptions struct {
+  // Maximum number of categories recorded per string column. If a column has
+  // more distinct values, its categories are not recorded.
+  // Default: 256. Negative values disable the recording of categories.
+  MaxCategories int
+}
+
+// CaptureSchema records the names, kinds, ranges and categories of the columns
+// of the given dataframe. Only the rows of the view are taken into account.
+// Columns are sorted by name.
+func CaptureSchema(df *DataFrame, options SchemaOptions) *Schema {
+  if options.MaxCategories == 0 {
+    options.MaxCategories = 256
+  }
+  names := df.Header().NameList()
+  sort.Strings(names)
+  result := &Schema{Columns: make([]ColumnSchema, len(names))}
+  for k, col := range names {
+    c := ColumnSchema{Name: col, Kind: df.columnKind(col)}
+    switch c.Kind {
+    case FloatKind:
+      vals := df.floats[col]
+      min, max := math.Inf(1), math.Inf(-1)
+      for _, i := range df.indices {
+        if math.IsNaN(vals[i]) {
+          c.HasMissing = true
+        } else {
+          min = math.Min(min, vals[i])
+          max = math.Max(max, vals[i])
+        }
+      }
+      c.setRange(min, max)
+    case IntKind:
+      vals := df.ints[col]
+      min, max := math.Inf(1), math.Inf(-1)
+      for _, i := range df.indices {
+        if vals[i] == -1 {
+          c.HasMissing = true
+        } else {
+          min = math.Min(min, float64(vals[i]))
+          max = math.Max(max, float64(vals[i]))
+        }
+      }
+      c.setRange(min, max)
+    case StringKind:
+      vals := df.objects[col]
+      categories := make(map[string]bool)
+      for _, i := range df.indices {
+        if vals[i] == nil {
+          c.HasMissing = true
+        } else if len(categories) <= options.MaxCategories {
+          categories[vals[i].(string)] = true
+        }
+      }
+      if len(categories) <= options.MaxCategories {
+        c.Categories = make([]string, 0, len(categories))
+        for category := range categories {
+          c.Categories = append(c.Categories, category)
+        }
+        sort.Strings(c.Categories)
+      }
+    case ObjectKind:
+      vals := df.objects[col]
+      for _, i := range df.indices {
+        if vals[i] == nil {
+          c.HasMissing = true
+          break
+        }
+      }
+    }
+    result.Columns[k] = c
+  }
+  return result
+}
+
+func (c *ColumnSchema) setRange(min float64, max float64) {
+  if min <= max {
+    c.Min = &min
+    c.Max = &max
+  }
+}
+
+// columnKind returns the kind of the given column, or an empty string if the
+// column doesn't exist.
+func (df *DataFrame) columnKind(colName string) string {
+  if _, ok := df.floats[colName]; ok {
+    return FloatKind
+  } else if _, ok := df.ints[colName]; ok {
+    return IntKind
+  } else if _, ok := df.bools[colName]; ok {
+    return BoolKind
+  } else if _, ok := df.objects[colName]; ok {
+    if df.stringHeader.contains(colName) {
+      return StringKind
+    }
+    return ObjectKind
+  }
+  return ""
+}
+
+// Names returns the names of the columns in the order of the schema, which is
+// useful to create iterators and matrices with a stable column order.
+func (schema *Schema) Names() []string {
+  result := make([]string, len(schema.Columns))
+  for k, c := range schema.Columns {
+    result[k] = c.Name
+  }
+  return result
+}
+
+// ColumnPolicy specifies how Conform handles a discrepancy between the schema
+// and the dataframe.
+type ColumnPolicy int
+
+const(
+  // The discrepancy is reported in the returned error.
+  RejectDiscrepancy ColumnPolicy = iota
+  // The discrepancy is ignored.
+  IgnoreDiscrepancy
+  // Missing columns are filled with missing values.
+  // Columns of the wrong type are converted, if the conversion is lossless.
+  // Extra columns are dropped.
+  // Out-of-range values are clipped.
+  // Unknown categories are replaced with missing values.
+  FixDiscrepancy
+)
+
+// ConformOptions specifies the policy of Schema.Conform for each kind of
+// discrepancy. All policies default to RejectDiscrepancy.
+type ConformOptions struct {
+  // Columns of the schema that the dataframe lacks.
+  MissingColumns    ColumnPolicy
+  // Columns of the dataframe that are not in the schema.
+  ExtraColumns      ColumnPolicy
+  // Columns whose type is different than in the schema.
+  TypeMismatches    ColumnPolicy
+  // Float and int values outside the range seen in the training data.
+  OutOfRange        ColumnPolicy
+  // Strings that were not seen in the training data.
+  UnknownCategories ColumnPolicy
+}
+
+// ConformProblem describes a discrepancy between a schema and a dataframe.
+type ConformProblem struct {
+  Column  string
+  Message string
+}
+
+// ConformError is the error returned by Schema.Conform.
+// It lists all the discrepancies that were rejected.
+type ConformError struct {
+  Problems []ConformProblem
+}
+
+func (err *ConformError) Error() string {
+  messages := make([]string, len(err.Problems))
+  for k, p := range err.Problems {
+    messages[k] = p.Message
+  }
+  return strings.Join(messages, "; ")
+}
+
+func (err *ConformError) add(col string, format string, args ...interface{}) {
+  err.Problems = append(err.Problems, ConformProblem{
+    Column: col,
+    Message: fmt.Sprintf(format, args...),
+  })
+}
+
+// Conform returns a view on the dataframe that complies with the schema,
+// according to the given policies.
+// Since dataframes don't order their columns, use Schema.Names() if you need
+// the columns to be ordered like in the training data.
+// Conform returns a *ConformError if at least one discrepancy is rejected or
+// cannot be fixed, e.g. a float column that holds non-integer values cannot
+// be converted to an int column, and a missing bool column cannot be filled
+// since bool columns don't have a missing value marker.
+// The input dataframe is never altered.
+func (schema *Schema) Conform(df *DataFrame, options ConformOptions) (*DataFrame, error) {
+  df.debugPrint("conforming")
+  report := &ConformError{}
+  result := df.View()
+  result.reallocateMaps()
+  for _, c := range schema.Columns {
+    kind := result.columnKind(c.Name)
+    if len(kind) == 0 {
+      switch options.MissingColumns {
+      case RejectDiscrepancy:
+        report.add(c.Name, "column %s is missing", c.Name)
+      case FixDiscrepancy:
+        if err := result.fillMissingColumn(c.Name, c.Kind); err != nil {
+          report.add(c.Name, err.Error())
+        }
+      }
+      continue
+    }
+    if kind != c.Kind {
+      if options.TypeMismatches == IgnoreDiscrepancy {
+        continue
+      }
+      if options.TypeMismatches == RejectDiscrepancy {
+        report.add(c.Name, "column %s is a %s column. Expected: %s", c.Name, kind, c.Kind)
+        continue
+      }
+      if err := result.convertColumn(c.Name, kind, c.Kind); err != nil {
+        report.add(c.Name, err.Error())
+        continue
+      }
+    }
+    if c.Min != nil && options.OutOfRange != IgnoreDiscrepancy {
+      result.conformRange(c, options.OutOfRange == FixDiscrepancy, report)
+    }
+    if c.Categories != nil && options.UnknownCategories != IgnoreDiscrepancy {
+      result.conformCategories(c, options.UnknownCategories == FixDiscrepancy, report)
+    }
+  }
+  if options.ExtraColumns != IgnoreDiscrepancy {
+    expected := utils.ToStringSet(schema.Names())
+    var extra []string
+    for col := range result.Header().NameSet() {
+      if !expected[col] {
+        extra = append(extra, col)
+      }
+    }
+    sort.Strings(extra)
+    if options.ExtraColumns == RejectDiscrepancy {
+      for _, col := range extra {
+        report.add(col, "column %s is not in the schema", col)
+      }
+    } else {
+      result.Drop(extra...)
+    }
+  }
+  if len(report.Problems) > 0 {
+    return nil, report
+  }
+  result.debugPrint("Conform() returns")
+
+  return result, nil
+}
+
+func (df *DataFrame) fillMissingColumn(colName string, kind string) error {
+  if df.NumColumns() == 0 {
+    return fmt.Errorf("column %s cannot be added to a dataframe without columns", colName)
+  }
+  switch kind {
+  case FloatKind:
+    df.AllocFloats(colName)
+    vals := df.floats[colName]
+    for i := range vals {
+      vals[i] = math.NaN()
+    }
+  case IntKind:
+    df.AllocInts(colName)
+    vals := df.ints[colName]
+    for i := range vals {
+      vals[i] = -1
+    }
+  case StringKind:
+    df.AllocStrings(colName)
+  case ObjectKind:
+    df.AllocObjects(colName)
+  default:
+    return fmt.Errorf("missing %s column %s cannot be filled with missing values", kind, colName)
+  }
+  return nil
+}
+
+// replaceColumn drops the given column and allocates an empty column of the
+// given kind under the same name.
+// Unlike Drop+Alloc, it preserves the number of allocated rows even if the
+// column was the only column of the dataframe.
+func (df *DataFrame) replaceColumn(colName string, kind string, nRows int) {
+  df.Drop(colName)
+  switch kind {
+  case FloatKind:
+    df.floats[colName] = make([]float64, nRows)
+  case IntKind:
+    df.ints[colName] = make([]int, nRows)
+  case BoolKind:
+    df.bools[colName] = make([]bool, nRows)
+  default:
+    df.objects[colName] = make([]interface{}, nRows)
+    if kind == StringKind {
+      df.stringHeader.add(colName)
+    }
+  }
+}
+
+// convertColumn replaces the given column with a new column of another kind.
+// It returns an error if the conversion would lose information.
+func (df *DataFrame) convertColumn(colName string, from string, to string) error {
+  n := len(df.indices)
+  nRows := df.NumAllocatedRows()
+  fail := func(j int) error {
+    return fmt.Errorf("%s column %s cannot be converted to %s at row %d",
+                      from, colName, to, j)
+  }
+  // read the values as floats, except for strings
+  var floats []float64
+  var objects []interface{}
+  switch from {
+  case FloatKind:
+    floats = make([]float64, n)
+    vals := df.floats[colName]
+    for j, i := range df.indices {
+      floats[j] = vals[i]
+    }
+  case IntKind:
+    floats = make([]float64, n)
+    vals := df.ints[colName]
+    for j, i := range df.indices {
+      if vals[i] == -1 {
+        floats[j] = math.NaN()
+      } else {
+        floats[j] = float64(vals[i])
+      }
+    }
+  case BoolKind:
+    floats = make([]float64, n)
+    vals := df.bools[colName]
+    for j, i := range df.indices {
+      if vals[i] {
+        floats[j] = 1
+      }
+    }
+  case StringKind:
+    objects = make([]interface{}, n)
+    vals := df.objects[colName]
+    for j, i := range df.indices {
+      objects[j] = vals[i]
+    }
+  default:
+    return fmt.Errorf("object column %s cannot be converted to %s", colName, to)
+  }
+  if objects != nil && to != StringKind && to != ObjectKind {
+    // parse the strings
+    floats = make([]float64, n)
+    for j, v := range objects {
+      if v == nil {
+        floats[j] = math.NaN()
+        continue
+      }
+      f, err := strconv.ParseFloat(strings.TrimSpace(v.(string)), 64)
+      if err != nil {
+        return fail(j)
+      }
+      floats[j] = f
+    }
+  }
+  switch to {
+  case FloatKind:
+    df.replaceColumn(colName, to, nRows)
+    df.OverwriteFloats64(colName, floats)
+  case IntKind:
+    ints := make([]int, n)
+    for j, f := range floats {
+      if math.IsNaN(f) {
+        ints[j] = -1
+      } else if f != math.Trunc(f) || f == -1 {
+        return fail(j)
+      } else {
+        ints[j] = int(f)
+      }
+    }
+    df.replaceColumn(colName, to, nRows)
+    df.OverwriteInts(colName, ints)
+  case BoolKind:
+    bools := make([]bool, n)
+    for j, f := range floats {
+      if f != 0 && f != 1 {
+        return fail(j)
+      }
+      bools[j] = f == 1
+    }
+    df.replaceColumn(colName, to, nRows)
+    df.OverwriteBools(colName, bools)
+  case StringKind, ObjectKind:
+    if objects == nil {
+      objects = make([]interface{}, n)
+      for j, f := range floats {
+        if math.IsNaN(f) {
+          continue
+        }
+        if from == BoolKind {
+          objects[j] = strconv.FormatBool(f == 1)
+        } else {
+          objects[j] = strconv.FormatFloat(f, 'g', -1, 64)
+        }
+      }
+    }
+    df.replaceColumn(colName, to, nRows)
+    if to == StringKind {
+      df.OverwriteObjects(colName, objects, StringObject)
+    } else {
+      df.OverwriteObjects(colName, objects, AnyObject)
+    }
+  }
+  return nil
+}
+
+func (df *DataFrame) conformRange(c ColumnSchema, clip bool, report *ConformError) {
+  min, max := *c.Min, *c.Max
+  var floats []float64
+  var ints []int
+  if c.Kind == FloatKind {
+    floats = df.floats[c.Name]
+  } else if c.Kind == IntKind {
+    ints = df.ints[c.Name]
+  } else {
+    return
+  }
+  outOfRange := func(i int) bool {
+    if floats != nil {
+      return floats[i] < min || floats[i] > max  // false if NaN
+    }
+    return ints[i] != -1 && (float64(ints[i]) < min || float64(ints[i]) > max)
+  }
+  first, count := -1, 0
+  for j, i := range df.indices {
+    if outOfRange(i) {
+      if first < 0 {
+        first = j
+      }
+      count++
+    }
+  }
+  if count == 0 {
+    return
+  }
+  if !clip {
+    report.add(c.Name, "column %s has %d values out of range [%g, %g], first at row %d",
+               c.Name, count, min, max, first)
+    return
+  }
+  df.Unshare(c.Name)
+  if floats != nil {
+    floats = df.floats[c.Name]
+    for _, i := range df.indices {
+      floats[i] = math.Max(min, math.Min(max, floats[i]))
+    }
+  } else {
+    ints = df.ints[c.Name]
+    for _, i := range df.indices {
+      if ints[i] != -1 {
+        ints[i] = int(math.Max(min, math.Min(max, float64(ints[i]))))
+      }
+    }
+  }
+}
+
+func (df *DataFrame) conformCategories(c ColumnSchema, replace bool, report *ConformError) {
+  if c.Kind != StringKind {
+    return
+  }
+  known := utils.ToStringSet(c.Categories)
+  vals := df.objects[c.Name]
+  var unknown []int
+  for j, i := range df.indices {
+    if vals[i] != nil && !known[vals[i].(string)] {
+      unknown = append(unknown, i)
+      if !replace {
+        report.add(c.Name, "column %s has unknown category '%s' at row %d", c.Name, vals[i], j)
+        return
+      }
+    }
+  }
+  if len(unknown) == 0 {
+    return
+  }
+  df.Unshare(c.Name)
+  vals = df.objects[c.Name]
+  for _, i := range unknown {
+    vals[i] = nil
+  }
+}
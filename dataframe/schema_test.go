@@ -0,0 +1,100 @@
+package dataframe
+
+import (
+    "math"
+    "testing"
+    "encoding/json"
+    u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func schemaTestData() *DataFrame {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("height", 1.5, math.NaN(), 1.8)
+  builder.AddInts("age", 20, 30, 40)
+  builder.AddStrings("country", "fr", "us", "fr")
+  builder.AddBools("active", true, false, true)
+  return builder.ToDataFrame()
+}
+
+func TestCaptureSchema(t *testing.T) {
+  schema := CaptureSchema(schemaTestData(), SchemaOptions{})
+  u.AssertStringSliceEquals("names", schema.Names(), []string{"active", "age", "country", "height"}, true, t)
+  height := schema.Columns[3]
+  u.AssertStringEquals("kind", height.Kind, FloatKind, t)
+  u.AssertTrue("missing", height.HasMissing, t)
+  u.AssertFloatEquals("min", *height.Min, 1.5, t)
+  u.AssertFloatEquals("max", *height.Max, 1.8, t)
+  u.AssertStringSliceEquals("categories", schema.Columns[2].Categories, []string{"fr", "us"}, true, t)
+
+  // json round trip
+  encoded, err := json.Marshal(schema)
+  u.AssertNoError(err, t)
+  decoded := &Schema{}
+  u.AssertNoError(json.Unmarshal(encoded, decoded), t)
+  u.AssertStringEquals("json", decoded.Columns[1].Kind, IntKind, t)
+  u.AssertFloatEquals("json", *decoded.Columns[1].Max, 40, t)
+}
+
+func TestConform(t *testing.T) {
+  schema := CaptureSchema(schemaTestData(), SchemaOptions{})
+
+  // serving data: age parsed as floats, country unknown, height missing
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("age", 25, 50)
+  builder.AddStrings("country", "fr", "de")
+  builder.AddBools("active", false, true)
+  builder.AddInts("extra", 1, 2)
+  df := builder.ToDataFrame()
+
+  _, err := schema.Conform(df, ConformOptions{})
+  report, ok := err.(*ConformError)
+  if !u.AssertTrue("conform error", ok, t) {
+    return
+  }
+  // height missing, age type, unknown country, extra column
+  u.AssertIntEquals("num problems", len(report.Problems), 4, t)
+
+  conformed, err := schema.Conform(df, ConformOptions{
+    MissingColumns: FixDiscrepancy,
+    ExtraColumns: FixDiscrepancy,
+    TypeMismatches: FixDiscrepancy,
+    OutOfRange: FixDiscrepancy,
+    UnknownCategories: FixDiscrepancy,
+  })
+  u.AssertNoError(err, t)
+  conformed.CheckConsistency(t)
+  u.AssertIntEquals("age", conformed.Ints("age").Get(0), 25, t)
+  u.AssertIntEquals("clipped age", conformed.Ints("age").Get(1), 40, t)
+  u.AssertTrue("height", math.IsNaN(conformed.Floats("height").Get(0)), t)
+  u.AssertTrue("unknown", conformed.Objects("country").Get(1) == nil, t)
+  u.AssertFalse("extra", conformed.Header().NameSet()["extra"], t)
+
+  // the input dataframe is not altered
+  u.AssertFloatEquals("original", df.Floats("age").Get(1), 50, t)
+  u.AssertStringEquals("original", df.Strings("country").Get(1), "de", t)
+  u.AssertTrue("original", df.Header().NameSet()["extra"], t)
+
+  // lossy conversion
+  builder = DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("age", 25.5)
+  intSchema := &Schema{Columns: []ColumnSchema{{Name: "age", Kind: IntKind}}}
+  _, err = intSchema.Conform(builder.ToDataFrame(), ConformOptions{TypeMismatches: FixDiscrepancy})
+  u.AssertTrue("lossy", err != nil, t)
+}
+
+func TestConformSingleColumn(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  schema := CaptureSchema(builder.AddFloats("x", 1.5, 2.5).ToDataFrame(), SchemaOptions{})
+
+  builder = DataBuilder{RawData: NewRawData()}
+  df := builder.AddInts("x", 3, -1, 2).ToDataFrame()
+  conformed, err := schema.Conform(df.SliceView(1, 3), ConformOptions{TypeMismatches: FixDiscrepancy})
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  conformed.CheckConsistency(t)
+  u.AssertIntEquals("num rows", conformed.NumRows(), 2, t)
+  u.AssertTrue("missing", math.IsNaN(conformed.Floats("x").Get(0)), t)
+  u.AssertFloatEquals("converted", conformed.Floats("x").Get(1), 2, t)
+  u.AssertIntEquals("original", df.Ints("x").Get(1), -1, t)
+}
@@ -0,0 +1,70 @@
+package dataframe
+
+import (
+  "fmt"
+  "sync"
+  "github.com/rom1mouret/ml-essentials/utils"
+)
+
+// QuantileSketch summarizes the given float or int column into a mergeable
+// quantile sketch. Missing values are ignored.
+// The dataframe is split into as many parts as MaxCPU and each part is
+// sketched in a separate go routine before the sketches are merged.
+// Sketches from different dataframes can be merged together and serialized,
+// e.g. to estimate the quantiles of data spread over multiple files.
+// If compression is zero or negative, it defaults to 200.
+// It panics if the column is neither a float column nor an int column.
+func (df *DataFrame) QuantileSketch(colName string, compression float64) *utils.QuantileSketch {
+  df.debugPrint("sketching")
+  kind := df.columnKind(colName)
+  if kind != FloatKind && kind != IntKind {
+    panic(fmt.Sprintf("%s is neither a float column nor an int column", colName))
+  }
+  parts := df.SplitNView(df.ActualMaxCPU())
+  sketches := make([]*utils.QuantileSketch, len(parts))
+  var wg sync.WaitGroup
+  wg.Add(len(parts))
+  for i, part := range parts {
+    sketches[i] = utils.NewQuantileSketch(compression)
+    go func(part *DataFrame, sketch *utils.QuantileSketch) {
+      defer wg.Done()
+      if part.NumRows() == 0 {
+        return  // SplitNView's filler dataframes have no columns
+      }
+      if kind == FloatKind {
+        access := part.Floats(colName)
+        for j := 0; j < access.Size(); j++ {
+          sketch.Add(access.Get(j))
+        }
+      } else {
+        access := part.Ints(colName)
+        for j := 0; j < access.Size(); j++ {
+          if v := access.Get(j); v != -1 {
+            sketch.Add(float64(v))
+          }
+        }
+      }
+    }(part, sketches[i])
+  }
+  wg.Wait()
+  for _, sketch := range sketches[1:] {
+    sketches[0].Merge(sketch)
+  }
+  return sketches[0]
+}
+
+// ApproxQuantiles estimates the given quantiles of a float or int column
+// without sorting the column. Quantiles are numbers between 0 and 1.
+// Missing values are ignored. If the column has no valid value, NaNs are
+// returned.
+// Use QuantileSketch instead if you need to combine the results of multiple
+// dataframes.
+// It panics if the column is neither a float column nor an int column.
+func (df *DataFrame) ApproxQuantiles(colName string, qs ...float64) []float64 {
+  sketch := df.QuantileSketch(colName, 0)
+  result := make([]float64, len(qs))
+  for i, q := range qs {
+    result[i] = sketch.Quantile(q)
+  }
+  return result
+}
@@ -0,0 +1,35 @@
+package dataframe
+
+import (
+  "math"
+  "testing"
+  u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func TestApproxQuantiles(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  for i := 0; i <= 1000; i++ {
+    builder.AddFloats("floats", float64(i))
+    builder.AddInts("ints", i % 11 - 1)
+  }
+  builder.AddFloats("floats", math.NaN())
+  builder.AddInts("ints", -1)
+  df := builder.ToDataFrame()
+  df.SetMaxCPU(4)
+
+  quantiles := df.ApproxQuantiles("floats", 0, 0.5, 0.9, 1)
+  u.AssertFloatEquals("min", quantiles[0], 0, t)
+  u.AssertTrue("median", math.Abs(quantiles[1] - 500) < 5, t)
+  u.AssertTrue("q90", math.Abs(quantiles[2] - 900) < 5, t)
+  u.AssertFloatEquals("max", quantiles[3], 1000, t)
+
+  // -1 is the missing value marker
+  u.AssertFloatEquals("ints", df.ApproxQuantiles("ints", 0)[0], 0, t)
+
+  // merging sketches of two dataframes
+  sketch := df.IndexView(u.MakeRange(0, 500, 1)).QuantileSketch("floats", 0)
+  sketch.Merge(df.IndexView(u.MakeRange(500, 1002, 1)).QuantileSketch("floats", 0))
+  u.AssertTrue("merged median", math.Abs(sketch.Quantile(0.5) - 500) < 5, t)
+
+  u.AssertTrue("not numerical", panics(func() { df.ApproxQuantiles("bools", 0.5) }), t)
+}
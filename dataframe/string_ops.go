@@ -0,0 +1,213 @@
+package dataframe
+
+import (
+  "fmt"
+  "regexp"
+  "strings"
+  "sync"
+)
+
+// StringOps runs vectorized operations on a string column.
+// Each operation returns a view of the dataframe with the result stored in
+// a new column, leaving the original dataframe unaltered. If the output
+// column already exists, it is replaced in the view.
+// Missing strings are passed through as missing values, i.e. nil in the
+// output string columns and -1 in the output int columns. Bool columns have
+// no missing value marker so missing strings are mapped to false.
+// The operations are multi-threaded.
+type StringOps struct {
+  df   *DataFrame
+  vals []interface{}
+}
+
+// Str returns the string operations of the given string column, e.g.
+//  df = df.Str("name").Lower("name")
+// It panics if the column is not a string column.
+func (df *DataFrame) Str(colName string) StringOps {
+  df.debugPrint("string operations")
+  vals, ok := df.objects[colName]
+  if !ok || !df.stringHeader.contains(colName) {
+    panic(fmt.Sprintf("%s is not in the list of string columns", colName))
+  }
+  return StringOps{df: df, vals: vals}
+}
+
+// forEach calls f on every row of the view, split between the workers.
+// j is the index to the backing data. ok is false if the value is missing.
+// Rows pointing to the same backing index are processed once, so that two
+// workers never write into the same slot.
+func (ops StringOps) forEach(f func(j int, s string, ok bool)) {
+  indices := ops.df.indices
+  if ops.df.indexViewed {
+    indices = make([]int, 0, len(ops.df.indices))
+    seen := make([]bool, len(ops.vals))
+    for _, j := range ops.df.indices {
+      if !seen[j] {
+        seen[j] = true
+        indices = append(indices, j)
+      }
+    }
+  }
+  numWorkers := ops.df.ActualMaxCPU()
+  if numWorkers > len(indices) {
+    numWorkers = len(indices)
+  }
+  var wg sync.WaitGroup
+  wg.Add(numWorkers)
+  for w := 0; w < numWorkers; w++ {
+    part := indices[w * len(indices) / numWorkers:(w + 1) * len(indices) / numWorkers]
+    go func() {
+      defer wg.Done()
+      for _, j := range part {
+        s, ok := ops.vals[j].(string)
+        f(j, s, ok)
+      }
+    }()
+  }
+  wg.Wait()
+}
+
+// outputView returns a view where the given columns are dropped so they can
+// be replaced by new columns.
+func (ops StringOps) outputView(columns ...string) *DataFrame {
+  result := ops.df.View()
+  result.reallocateMaps()
+  result.Drop(columns...)
+  result.dataUID |= generateDataUID()
+  return result
+}
+
+// mapStrings stores f's output in a new string column. If f returns false,
+// the value is treated as missing.
+func (ops StringOps) mapStrings(out string, context string, f func(s string) (string, bool)) *DataFrame {
+  result := ops.outputView(out)
+  vals := make([]interface{}, ops.df.NumAllocatedRows())
+  ops.forEach(func(j int, s string, ok bool) {
+    if ok {
+      if r, valid := f(s); valid {
+        vals[j] = r
+      }
+    }
+  })
+  result.objects[out] = vals
+  result.stringHeader.add(out)
+  result.debugValidate(context)
+  return result
+}
+
+// mapBools stores f's output in a new bool column.
+func (ops StringOps) mapBools(out string, context string, f func(s string) bool) *DataFrame {
+  result := ops.outputView(out)
+  vals := make([]bool, ops.df.NumAllocatedRows())
+  ops.forEach(func(j int, s string, ok bool) {
+    vals[j] = ok && f(s)
+  })
+  result.bools[out] = vals
+  result.debugValidate(context)
+  return result
+}
+
+// Lower converts the strings to lower case and stores them in column out.
+func (ops StringOps) Lower(out string) *DataFrame {
+  return ops.mapStrings(out, "Str().Lower()", func(s string) (string, bool) {
+    return strings.ToLower(s), true
+  })
+}
+
+// Upper converts the strings to upper case and stores them in column out.
+func (ops StringOps) Upper(out string) *DataFrame {
+  return ops.mapStrings(out, "Str().Upper()", func(s string) (string, bool) {
+    return strings.ToUpper(s), true
+  })
+}
+
+// Trim removes the leading and trailing white spaces and stores the result in
+// column out.
+func (ops StringOps) Trim(out string) *DataFrame {
+  return ops.mapStrings(out, "Str().Trim()", func(s string) (string, bool) {
+    return strings.TrimSpace(s), true
+  })
+}
+
+// Replace replaces all the occurrences of old with new and stores the result
+// in column out.
+func (ops StringOps) Replace(out string, old string, new string) *DataFrame {
+  return ops.mapStrings(out, "Str().Replace()", func(s string) (string, bool) {
+    return strings.ReplaceAll(s, old, new), true
+  })
+}
+
+// Extract stores the first match of the regular expression in column out.
+// If the expression has capturing groups, only the first group is stored.
+// Strings that don't match are stored as missing values.
+func (ops StringOps) Extract(out string, re *regexp.Regexp) *DataFrame {
+  return ops.mapStrings(out, "Str().Extract()", func(s string) (string, bool) {
+    match := re.FindStringSubmatch(s)
+    if match == nil {
+      return "", false
+    } else if len(match) > 1 {
+      return match[1], true
+    }
+    return match[0], true
+  })
+}
+
+// Match stores in bool column out whether the strings match the regular
+// expression.
+func (ops StringOps) Match(out string, re *regexp.Regexp) *DataFrame {
+  return ops.mapBools(out, "Str().Match()", re.MatchString)
+}
+
+// Contains stores in bool column out whether the strings contain substr.
+func (ops StringOps) Contains(out string, substr string) *DataFrame {
+  return ops.mapBools(out, "Str().Contains()", func(s string) bool {
+    return strings.Contains(s, substr)
+  })
+}
+
+// Len stores the number of characters of the strings in int column out.
+func (ops StringOps) Len(out string) *DataFrame {
+  result := ops.outputView(out)
+  vals := make([]int, ops.df.NumAllocatedRows())
+  ops.forEach(func(j int, s string, ok bool) {
+    if ok {
+      vals[j] = len([]rune(s))
+    } else {
+      vals[j] = -1
+    }
+  })
+  result.ints[out] = vals
+  result.debugValidate("Str().Len()")
+  return result
+}
+
+// Split splits the strings around sep and stores the parts in the columns
+// given as argument, the first part in outs[0], the second in outs[1] etc.
+// The last column receives the unsplit remainder of the string, if any.
+// Strings with fewer parts than columns have missing values in the remaining
+// columns.
+// It panics if no output column is given.
+func (ops StringOps) Split(sep string, outs ...string) *DataFrame {
+  if len(outs) == 0 {
+    panic("Split requires at least one output column")
+  }
+  result := ops.outputView(outs...)
+  nRows := ops.df.NumAllocatedRows()
+  columns := make([][]interface{}, len(outs))
+  for k := range columns {
+    columns[k] = make([]interface{}, nRows)
+  }
+  ops.forEach(func(j int, s string, ok bool) {
+    if ok {
+      for k, part := range strings.SplitN(s, sep, len(outs)) {
+        columns[k][j] = part
+      }
+    }
+  })
+  for k, out := range outs {
+    result.objects[out] = columns[k]
+    result.stringHeader.add(out)
+  }
+  result.debugValidate("Str().Split()")
+  return result
+}
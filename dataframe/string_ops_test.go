@@ -0,0 +1,84 @@
+package dataframe
+
+import (
+  "regexp"
+  "testing"
+  u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func stringOpsTestData() *DataFrame {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddStrings("name", " Karen Smith", "John Doe ", "Élise")
+  builder.AddFloats("height", 170, 180, 165)
+  df := builder.ToDataFrame()
+  df.Objects("name").Set(2, nil)
+  return df
+}
+
+func TestStringOps(t *testing.T) {
+  df := stringOpsTestData().ReverseView()
+  trimmed := df.Str("name").Trim("name")
+  trimmed.CheckConsistency(t)
+  u.AssertStringEquals("trim", trimmed.Strings("name").Get(1), "John Doe", t)
+  u.AssertTrue("missing", trimmed.Objects("name").Get(0) == nil, t)
+  u.AssertStringEquals("original", df.Strings("name").Get(1), "John Doe ", t)
+
+  upper := trimmed.Str("name").Upper("upper")
+  u.AssertStringEquals("upper", upper.Strings("upper").Get(2), "KAREN SMITH", t)
+  lower := upper.Str("upper").Lower("lower")
+  u.AssertStringEquals("lower", lower.Strings("lower").Get(2), "karen smith", t)
+  replaced := trimmed.Str("name").Replace("name", "Doe", "Roe")
+  u.AssertStringEquals("replace", replaced.Strings("name").Get(1), "John Roe", t)
+
+  lengths := trimmed.Str("name").Len("name")
+  u.AssertIntEquals("num strings", lengths.StringHeader().Num(), 0, t)
+  u.AssertIntEquals("len", lengths.Ints("name").Get(1), 8, t)
+  u.AssertIntEquals("len missing", lengths.Ints("name").Get(0), -1, t)
+
+  contains := trimmed.Str("name").Contains("has_doe", "Doe")
+  u.AssertFalse("contains missing", contains.Bools("has_doe").Get(0), t)
+  u.AssertTrue("contains", contains.Bools("has_doe").Get(1), t)
+  u.AssertFalse("does not contain", contains.Bools("has_doe").Get(2), t)
+}
+
+func TestStringOpsRepeatedIndices(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  names := make([]string, 100)
+  indices := make([]int, 0, 4 * len(names))
+  for i := range names {
+    names[i] = "A"
+  }
+  // every worker gets the same indices
+  for k := 0; k < 4; k++ {
+    for i := range names {
+      indices = append(indices, i)
+    }
+  }
+  df := builder.AddStrings("name", names...).ToDataFrame().IndexView(indices)
+  df.maxCPU = 4  // even on a single core
+  lower := df.Str("name").Lower("lower")
+  lower.CheckConsistency(t)
+  u.AssertIntEquals("num rows", lower.NumRows(), len(indices), t)
+  for i := 0; i < lower.NumRows(); i++ {
+    u.AssertStringEquals("lower", lower.Strings("lower").Get(i), "a", t)
+  }
+}
+
+func TestStringOpsRegexAndSplit(t *testing.T) {
+  df := stringOpsTestData().Str("name").Trim("name")
+  matched := df.Str("name").Match("match", regexp.MustCompile("^J"))
+  u.AssertTrue("match", matched.Bools("match").Get(1), t)
+  u.AssertFalse("no match", matched.Bools("match").Get(0), t)
+
+  extracted := df.Str("name").Extract("last", regexp.MustCompile(`\s(\w+)$`))
+  u.AssertStringEquals("extract", extracted.Strings("last").Get(0), "Smith", t)
+
+  split := df.Str("name").Split(" ", "first", "last", "other")
+  split.CheckConsistency(t)
+  u.AssertStringEquals("first", split.Strings("first").Get(1), "John", t)
+  u.AssertStringEquals("last", split.Strings("last").Get(1), "Doe", t)
+  u.AssertTrue("other", split.Objects("other").Get(1) == nil, t)
+  u.AssertTrue("missing", split.Objects("first").Get(2) == nil, t)
+
+  u.AssertTrue("not a string column", panics(func() { df.Str("height") }), t)
+}
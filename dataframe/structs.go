@@ -0,0 +1,414 @@
+package dataframe
+
+import (
+  "fmt"
+  "math"
+  "reflect"
+  "strconv"
+  "strings"
+)
+
+type fieldKind uint8
+const(
+  floatField fieldKind = iota
+  intField
+  boolField
+  stringField
+  objectField
+)
+
+// structField describes how a field of a struct maps to a column.
+type structField struct {
+  index   int
+  column  string
+  kind    fieldKind
+}
+
+// structFields parses the `mle` tags of a struct type.
+// Tags are formatted as `mle:"name,kind"` where both name and kind are
+// optional. kind is one of float, int, bool, string or object. Fields tagged
+// with `mle:"-"` and unexported fields are ignored.
+func structFields(t reflect.Type) ([]structField, error) {
+  result := make([]structField, 0, t.NumField())
+  for k := 0; k < t.NumField(); k++ {
+    field := t.Field(k)
+    if field.PkgPath != "" {
+      continue  // unexported
+    }
+    tag := field.Tag.Get("mle")
+    if tag == "-" {
+      continue
+    }
+    name := field.Name
+    var kindName string
+    if len(tag) > 0 {
+      parts := strings.Split(tag, ",")
+      if len(parts[0]) > 0 {
+        name = parts[0]
+      }
+      if len(parts) > 1 {
+        kindName = parts[1]
+      }
+    }
+    kind, err := fieldKindOf(field.Type, kindName)
+    if err != nil {
+      return nil, fmt.Errorf("field %s: %s", field.Name, err.Error())
+    }
+    result = append(result, structField{index: k, column: name, kind: kind})
+  }
+  return result, nil
+}
+
+func fieldKindOf(t reflect.Type, kindName string) (fieldKind, error) {
+  if t.Kind() == reflect.Ptr {
+    t = t.Elem()
+  }
+  isFloat := t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64
+  isInt := false
+  switch t.Kind() {
+  case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+       reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+    isInt = true
+  }
+  switch kindName {
+  case "":
+    if isFloat {
+      return floatField, nil
+    } else if isInt {
+      return intField, nil
+    } else if t.Kind() == reflect.Bool {
+      return boolField, nil
+    } else if t.Kind() == reflect.String {
+      return stringField, nil
+    }
+    return objectField, nil
+  case "float":
+    if !isFloat && !isInt {
+      return 0, fmt.Errorf("%s cannot be stored in a float column", t)
+    }
+    return floatField, nil
+  case "int":
+    if !isInt {
+      return 0, fmt.Errorf("%s cannot be stored in an int column", t)
+    }
+    return intField, nil
+  case "bool":
+    if t.Kind() != reflect.Bool {
+      return 0, fmt.Errorf("%s cannot be stored in a bool column", t)
+    }
+    return boolField, nil
+  case "string":
+    return stringField, nil
+  case "object":
+    return objectField, nil
+  }
+  return 0, fmt.Errorf("unknown column kind '%s'", kindName)
+}
+
+// FromStructs creates a RawData structure from a slice of structs or a slice
+// of pointers to structs.
+// Each exported field becomes a column. The column names and types can be
+// customized with struct tags such as:
+//  type Person struct {
+//    Name    string   `mle:"name"`
+//    Age     *int     `mle:"age,float"`
+//    Country int      `mle:"country,string"`
+//    Secret  string   `mle:"-"`
+//  }
+// The second part of the tag is the type of the column, either float, int,
+// bool, string or object. If omitted, floats are stored in float columns,
+// integers in int columns, bools in bool columns, strings in string columns
+// and everything else in object columns. Values stored in a string column are
+// formatted with fmt.Sprint if they are not already strings.
+// Nil pointers are converted to missing values, i.e. NaN, -1 or nil depending
+// on the type of the column. Bool columns cannot hold missing values, so
+// FromStructs returns an error if it comes across a nil *bool.
+// It also returns an error if the argument is not a slice of structs.
+// The data is copied, so the slice can be safely altered afterwards.
+func FromStructs(slice interface{}) (*RawData, error) {
+  v := reflect.ValueOf(slice)
+  if v.Kind() != reflect.Slice {
+    return nil, fmt.Errorf("FromStructs expects a slice, got %s", v.Kind())
+  }
+  elemType := v.Type().Elem()
+  isPtr := elemType.Kind() == reflect.Ptr
+  if isPtr {
+    elemType = elemType.Elem()
+  }
+  if elemType.Kind() != reflect.Struct {
+    return nil, fmt.Errorf("FromStructs expects a slice of structs, got %s", v.Type())
+  }
+  fields, err := structFields(elemType)
+  if err != nil {
+    return nil, err
+  }
+  // allocation
+  nRows := v.Len()
+  data := NewRawData()
+  for _, f := range fields {
+    switch f.kind {
+    case floatField:
+      data.floats[f.column] = make([]float64, nRows)
+    case intField:
+      data.ints[f.column] = make([]int, nRows)
+    case boolField:
+      data.bools[f.column] = make([]bool, nRows)
+    case stringField:
+      data.objects[f.column] = make([]interface{}, nRows)
+      data.stringHeader.add(f.column)
+    case objectField:
+      data.objects[f.column] = make([]interface{}, nRows)
+    }
+  }
+  // copy the data
+  for row := 0; row < nRows; row++ {
+    elem := v.Index(row)
+    if isPtr {
+      if elem.IsNil() {
+        return nil, fmt.Errorf("element %d is a nil pointer", row)
+      }
+      elem = elem.Elem()
+    }
+    for _, f := range fields {
+      fv := elem.Field(f.index)
+      if f.kind == objectField {
+        data.objects[f.column][row] = fv.Interface()
+        continue
+      }
+      if fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+        if fv.IsNil() {
+          switch f.kind {
+          case floatField:
+            data.floats[f.column][row] = math.NaN()
+          case intField:
+            data.ints[f.column][row] = -1
+          case boolField:
+            return nil, fmt.Errorf("nil bool in field %s at element %d", f.column, row)
+          }
+          // nil is already the missing marker of string columns
+          continue
+        }
+        fv = fv.Elem()
+      }
+      switch f.kind {
+      case floatField:
+        data.floats[f.column][row] = reflectToFloat(fv)
+      case intField:
+        data.ints[f.column][row] = reflectToInt(fv)
+      case boolField:
+        data.bools[f.column][row] = fv.Bool()
+      case stringField:
+        if fv.Kind() == reflect.String {
+          data.objects[f.column][row] = fv.String()
+        } else {
+          data.objects[f.column][row] = fmt.Sprint(fv.Interface())
+        }
+      }
+    }
+  }
+  return data, nil
+}
+
+func reflectToFloat(v reflect.Value) float64 {
+  switch v.Kind() {
+  case reflect.Float32, reflect.Float64:
+    return v.Float()
+  case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+    return float64(v.Uint())
+  }
+  return float64(v.Int())
+}
+
+func reflectToInt(v reflect.Value) int {
+  switch v.Kind() {
+  case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+    return int(v.Uint())
+  }
+  return int(v.Int())
+}
+
+// ToStructs fills a slice of structs with the rows of the dataframe, in the
+// same order as the dataframe's rows.
+// out must be a pointer to a slice of structs or a pointer to a slice of
+// pointers to structs. The slice is reallocated with df.NumRows() elements.
+// Fields are mapped to columns following the same `mle` tags as FromStructs.
+// Missing values are converted to nil if the field is a pointer. Otherwise, the
+// field is set to NaN for floats, including missing values of int columns,
+// -1 for ints and the zero value for strings and objects.
+// Strings of string columns are parsed if the field is numeric or bool, so
+// that fields tagged with `mle:"name,string"` can be read back.
+// It returns an error if a column is missing from the dataframe, if the type
+// of a column is not compatible with the type of its field, if a string
+// cannot be parsed, if NaN is stored in an int field that is not a pointer or
+// if an int doesn't fit in its field, e.g. a negative int in a uint field.
+func (df *DataFrame) ToStructs(out interface{}) error {
+  ptr := reflect.ValueOf(out)
+  if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Slice {
+    return fmt.Errorf("ToStructs expects a pointer to a slice, got %s", ptr.Type())
+  }
+  sliceType := ptr.Elem().Type()
+  elemType := sliceType.Elem()
+  isPtr := elemType.Kind() == reflect.Ptr
+  if isPtr {
+    elemType = elemType.Elem()
+  }
+  if elemType.Kind() != reflect.Struct {
+    return fmt.Errorf("ToStructs expects a slice of structs, got %s", sliceType)
+  }
+  fields, err := structFields(elemType)
+  if err != nil {
+    return err
+  }
+  // allocate the structs
+  nRows := len(df.indices)
+  result := reflect.MakeSlice(sliceType, nRows, nRows)
+  elems := make([]reflect.Value, nRows)
+  for j := range elems {
+    if isPtr {
+      p := reflect.New(elemType)
+      result.Index(j).Set(p)
+      elems[j] = p.Elem()
+    } else {
+      elems[j] = result.Index(j)
+    }
+  }
+  // fill the fields column by column
+  for _, f := range fields {
+    fieldType := elemType.Field(f.index).Type
+    baseType := fieldType
+    if fieldType.Kind() == reflect.Ptr {
+      baseType = fieldType.Elem()
+    }
+    if vals, ok := df.floats[f.column]; ok {
+      if f.kind != floatField && f.kind != intField {
+        return fmt.Errorf("float column %s cannot be stored in field of type %s", f.column, fieldType)
+      }
+      for j, i := range df.indices {
+        if math.IsNaN(vals[i]) {
+          if fieldType.Kind() == reflect.Ptr {
+            continue
+          }
+          if f.kind == intField {
+            return fmt.Errorf("NaN in float column %s cannot be stored in field of type %s", f.column, fieldType)
+          }
+        }
+        setField(elems[j].Field(f.index), reflect.ValueOf(vals[i]).Convert(baseType))
+      }
+    } else if vals, ok := df.ints[f.column]; ok {
+      if f.kind != intField && f.kind != floatField {
+        return fmt.Errorf("int column %s cannot be stored in field of type %s", f.column, fieldType)
+      }
+      for j, i := range df.indices {
+        if vals[i] == -1 {
+          if fieldType.Kind() == reflect.Ptr {
+            continue
+          }
+          if f.kind == floatField {
+            setField(elems[j].Field(f.index), reflect.ValueOf(math.NaN()).Convert(baseType))
+            continue
+          }
+        }
+        v, err := intValue(vals[i], baseType)
+        if err != nil {
+          return fmt.Errorf("column %s: %s", f.column, err.Error())
+        }
+        setField(elems[j].Field(f.index), v)
+      }
+    } else if vals, ok := df.bools[f.column]; ok {
+      if f.kind != boolField {
+        return fmt.Errorf("bool column %s cannot be stored in field of type %s", f.column, fieldType)
+      }
+      for j, i := range df.indices {
+        setField(elems[j].Field(f.index), reflect.ValueOf(vals[i]).Convert(baseType))
+      }
+    } else if vals, ok := df.objects[f.column]; ok {
+      for j, i := range df.indices {
+        if vals[i] == nil {
+          continue
+        }
+        v := reflect.ValueOf(vals[i])
+        field := elems[j].Field(f.index)
+        if v.Type().AssignableTo(fieldType) {
+          field.Set(v)
+        } else if v.Type().ConvertibleTo(baseType) && v.Kind() == baseType.Kind() {
+          setField(field, v.Convert(baseType))
+        } else if str, isString := vals[i].(string); isString && f.kind == stringField {
+          parsed, err := parseString(str, baseType)
+          if err != nil {
+            return fmt.Errorf("column %s: %s", f.column, err.Error())
+          }
+          setField(field, parsed)
+        } else {
+          return fmt.Errorf("%s value of column %s cannot be stored in field of type %s",
+                            v.Type(), f.column, fieldType)
+        }
+      }
+    } else {
+      return fmt.Errorf("column %s does not exist", f.column)
+    }
+  }
+  ptr.Elem().Set(result)
+
+  return nil
+}
+
+// intValue converts an int into a value of the given type. It returns an error
+// if the int doesn't fit in an int or uint type.
+func intValue(n int, t reflect.Type) (reflect.Value, error) {
+  v := reflect.New(t).Elem()
+  switch t.Kind() {
+  case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+    if v.OverflowInt(int64(n)) {
+      return v, fmt.Errorf("%d overflows field of type %s", n, t)
+    }
+  case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+    if n < 0 || v.OverflowUint(uint64(n)) {
+      return v, fmt.Errorf("%d overflows field of type %s", n, t)
+    }
+  }
+  return reflect.ValueOf(n).Convert(t), nil
+}
+
+// parseString parses a string formatted by FromStructs into a value of the
+// given type, e.g. an int stored in a string column.
+func parseString(s string, t reflect.Type) (reflect.Value, error) {
+  v := reflect.New(t).Elem()
+  var err error
+  switch t.Kind() {
+  case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+    var n int64
+    if n, err = strconv.ParseInt(s, 10, t.Bits()); err == nil {
+      v.SetInt(n)
+    }
+  case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+    var n uint64
+    if n, err = strconv.ParseUint(s, 10, t.Bits()); err == nil {
+      v.SetUint(n)
+    }
+  case reflect.Float32, reflect.Float64:
+    var f float64
+    if f, err = strconv.ParseFloat(s, t.Bits()); err == nil {
+      v.SetFloat(f)
+    }
+  case reflect.Bool:
+    var b bool
+    if b, err = strconv.ParseBool(s); err == nil {
+      v.SetBool(b)
+    }
+  default:
+    return v, fmt.Errorf("string cannot be stored in field of type %s", t)
+  }
+  return v, err
+}
+
+// setField sets a field or, if the field is a pointer, the value pointed by
+// a newly allocated pointer.
+func setField(field reflect.Value, v reflect.Value) {
+  if field.Kind() == reflect.Ptr {
+    p := reflect.New(field.Type().Elem())
+    p.Elem().Set(v)
+    field.Set(p)
+  } else {
+    field.Set(v)
+  }
+}
@@ -0,0 +1,165 @@
+package dataframe
+
+import (
+    "testing"
+    "math"
+    u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+type testingPerson struct {
+  Name     string   `mle:"name"`
+  Age      *int     `mle:"age"`
+  Height   float64
+  Adult    bool     `mle:"adult"`
+  Country  int      `mle:"country,string"`
+  Level    int      `mle:"level,float"`
+  Secret   string   `mle:"-"`
+  private  int
+}
+
+func TestFromStructs(t *testing.T) {
+  age := 42
+  people := []*testingPerson{
+    {Name: "Karen", Age: &age, Height: 170, Adult: true, Country: 33, Level: 2},
+    {Name: "John", Height: 180, Country: 1, Level: 3, Secret: "s"},
+  }
+  data, err := FromStructs(people)
+  if !u.AssertNoError(err, t) || !data.CheckConsistency(t) {
+    return
+  }
+  df := data.ToDataFrame()
+  u.AssertIntEquals("num columns", df.NumColumns(), 6, t)
+  u.AssertStringSliceEquals("strings", df.StringHeader().NameList(), []string{"name", "country"}, false, t)
+  u.AssertIntSliceEquals("age", df.ints["age"], []int{42, -1}, t)
+  u.AssertFloatSliceEquals("level", df.floats["level"], []float64{2, 3}, t)
+  u.AssertStringEquals("country", df.Strings("country").Get(0), "33", t)
+  u.AssertBoolSliceEquals("adult", df.bools["adult"], []bool{true, false}, t)
+}
+
+func TestFromStructsErrors(t *testing.T) {
+  _, err := FromStructs(42)
+  u.AssertTrue("not a slice", err != nil, t)
+  type wrongTag struct {
+    Name string `mle:"name,float"`
+  }
+  _, err = FromStructs([]wrongTag{{"a"}})
+  u.AssertTrue("wrong tag", err != nil, t)
+}
+
+func TestToStructs(t *testing.T) {
+  type record struct {
+    Name    *string  `mle:"name"`
+    Age     *int     `mle:"age"`
+    Height  float64  `mle:"height"`
+    Weight  *float32 `mle:"weight"`
+    Adult   bool     `mle:"adult"`
+  }
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddObjects("name", "Karen", nil, "Sophie").MarkAsString("name")
+  builder.AddInts("age", 42, -1, 20)
+  builder.AddFloats("height", 170, 180, 165)
+  builder.AddFloats("weight", 60, math.NaN(), 55)
+  builder.AddBools("adult", true, true, false)
+  df := builder.ToDataFrame().ReverseView()
+
+  var records []record
+  if !u.AssertNoError(df.ToStructs(&records), t) {
+    return
+  }
+  if !u.AssertIntEquals("num records", len(records), 3, t) {
+    return
+  }
+  u.AssertStringEquals("name", *records[0].Name, "Sophie", t)
+  u.AssertTrue("nil name", records[1].Name == nil, t)
+  u.AssertTrue("nil age", records[1].Age == nil, t)
+  u.AssertTrue("nil weight", records[1].Weight == nil, t)
+  u.AssertIntEquals("age", *records[2].Age, 42, t)
+  u.AssertFloatEquals("height", records[2].Height, 170, t)
+  u.AssertTrue("adult", records[1].Adult, t)
+
+  // round trip
+  data, err := FromStructs(records)
+  if u.AssertNoError(err, t) {
+    u.AssertIntSliceEquals("age", data.ints["age"], []int{20, -1, 42}, t)
+  }
+}
+
+func TestToStructsMissingColumn(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddFloats("height", 170, 180, 165)
+  var records []testingPerson
+  err := builder.ToDataFrame().ToStructs(&records)
+  u.AssertTrue("missing column", err != nil, t)
+}
+
+func TestStructsStringRoundTrip(t *testing.T) {
+  type record struct {
+    Country int      `mle:"country,string"`
+    Code    *uint8   `mle:"code,string"`
+    Ratio   float32  `mle:"ratio,string"`
+    Adult   bool     `mle:"adult,string"`
+  }
+  code := uint8(7)
+  records := []record{{Country: 33, Code: &code, Ratio: 0.5, Adult: true}, {Country: -2}}
+  data, err := FromStructs(records)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  var result []record
+  if !u.AssertNoError(data.ToDataFrame().ToStructs(&result), t) {
+    return
+  }
+  u.AssertIntEquals("country", result[0].Country, 33, t)
+  u.AssertIntEquals("negative country", result[1].Country, -2, t)
+  u.AssertIntEquals("code", int(*result[0].Code), 7, t)
+  u.AssertTrue("nil code", result[1].Code == nil, t)
+  u.AssertFloatEquals("ratio", float64(result[0].Ratio), 0.5, t)
+  u.AssertTrue("adult", result[0].Adult, t)
+
+  // unparsable string
+  builder := DataBuilder{RawData: NewRawData()}
+  builder.AddStrings("country", "france")
+  builder.AddStrings("code", "1")
+  builder.AddStrings("ratio", "1")
+  builder.AddStrings("adult", "true")
+  u.AssertTrue("unparsable", builder.ToDataFrame().ToStructs(&result) != nil, t)
+}
+
+func TestToStructsNaNInt(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  df := builder.AddFloats("level", 3, math.NaN()).ToDataFrame()
+  var records []struct {
+    Level int `mle:"level"`
+  }
+  u.AssertTrue("NaN into int", df.ToStructs(&records) != nil, t)
+
+  var pointers []struct {
+    Level *int `mle:"level"`
+  }
+  if u.AssertNoError(df.ToStructs(&pointers), t) {
+    u.AssertIntEquals("level", *pointers[0].Level, 3, t)
+    u.AssertTrue("nil level", pointers[1].Level == nil, t)
+  }
+}
+
+func TestToStructsIntConversions(t *testing.T) {
+  builder := DataBuilder{RawData: NewRawData()}
+  df := builder.AddInts("level", 3, -1, 300).ToDataFrame()
+  var floats []struct {
+    Level float64 `mle:"level"`
+  }
+  if u.AssertNoError(df.ToStructs(&floats), t) {
+    u.AssertFloatEquals("level", floats[0].Level, 3, t)
+    u.AssertTrue("missing", math.IsNaN(floats[1].Level), t)
+  }
+
+  var unsigned []struct {
+    Level uint `mle:"level"`
+  }
+  u.AssertTrue("negative", df.ToStructs(&unsigned) != nil, t)
+  u.AssertNoError(df.SliceView(2, 3).ToStructs(&unsigned), t)
+  var small []struct {
+    Level uint8 `mle:"level"`
+  }
+  u.AssertTrue("overflow", df.SliceView(2, 3).ToStructs(&small) != nil, t)
+}
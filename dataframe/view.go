@@ -4,7 +4,6 @@ import (
   "fmt"
   "sort"
   "math/rand"
-  "hash/fnv"
   "github.com/rom1mouret/ml-essentials/utils"
 )
 
@@ -35,6 +34,7 @@ func (df *DataFrame) IndexView(indices []int) *DataFrame {
   }
   result.indexViewed = true
 
+  result.debugValidate("IndexView()")
   return result
 }
 
@@ -83,6 +83,7 @@ func (df *DataFrame) MaskView(mask []bool) *DataFrame {
   result.indices = result.indices[:newSize]  // truncation
   result.indexViewed = true
 
+  result.debugValidate("MaskView()")
   return result
 }
 
@@ -124,6 +125,7 @@ func (df *DataFrame) ColumnView(columns ...string) *DataFrame {
       result.ints[col] = v
     }
   }
+  result.debugValidate("ColumnView()")
   return result
 }
 
@@ -156,6 +158,7 @@ func (df *DataFrame) SampleView(n int, replacement bool) *DataFrame {
   df = df.ShuffleView()
   df.indices = df.indices[:n]
 
+  df.debugValidate("SampleView()")
   return df
 }
 
@@ -309,6 +312,7 @@ func (df *DataFrame) TopView(byColumn string, n int, ascending bool, sorted bool
       result = result.ReverseView()
     }
   }
+  result.debugValidate("TopView()")
   return result
 }
 
@@ -321,6 +325,7 @@ func (df *DataFrame) ReverseView() *DataFrame {
   for i := 0; i < size; i++ {
     result.indices[i] = df.indices[size - i - 1]
   }
+  result.debugValidate("ReverseView()")
   return result
 }
 
@@ -328,8 +333,7 @@ func (df *DataFrame) ReverseView() *DataFrame {
 // 64-bit system.
 const maxInt = uint64(^uint(0) >> 1)
 
-func (inputDF *DataFrame) workerHashes(outputDF *DataFrame, columnQ utils.StringQ) {
-  hash := fnv.New64a()  // TODO: benchmark this algorithm
+func (inputDF *DataFrame) workerHashes(outputDF *DataFrame, hash func(s string) int, columnQ utils.StringQ) {
   for col := columnQ.Next(); len(col) > 0; col = columnQ.Next() {
     defer columnQ.Notify(utils.ProcessedJob{Key: col})
     inputCol := inputDF.objects[col]
@@ -340,9 +344,7 @@ func (inputDF *DataFrame) workerHashes(outputDF *DataFrame, columnQ utils.String
         outputCol[i] = -1 // missing value marker
       } else {
         if str, valid := v.(string); valid {
-          hash.Reset()
-          hash.Write([]byte(str))
-          outputCol[i] = int(hash.Sum64() % maxInt)
+          outputCol[i] = hash(str)
         } else {
           outputCol[i] = -1 // a bit controversial I suppose
         }
@@ -359,6 +361,17 @@ func (inputDF *DataFrame) workerHashes(outputDF *DataFrame, columnQ utils.String
 // categorical encoding.
 // HashStringsView is multi-threaded.
 func (df *DataFrame) HashStringsView(columns ...string) *DataFrame {
+  return df.HashStringsFuncView(func(s string) int {
+    return int(utils.FNV64a([]byte(s), 0) % maxInt)
+  }, columns...)
+}
+
+// HashStringsFuncView is like HashStringsView, but the strings are hashed with
+// the given function. The function should return non-negative integers, since
+// -1 is reserved for missing values.
+// The function must be safe for concurrent use.
+// HashStringsFuncView is multi-threaded.
+func (df *DataFrame) HashStringsFuncView(hash func(s string) int, columns ...string) *DataFrame {
   if len(columns) == 0 {
     return df.View()
   }
@@ -376,9 +389,10 @@ func (df *DataFrame) HashStringsView(columns ...string) *DataFrame {
   }
   // run the conversions in thread
   q := df.CreateColumnQueue(columns)
+  defer result.debugValidate("HashStringsFuncView()")  // after q.Wait()
   defer q.Wait()
   for i := 0; i < q.Workers; i++ {
-    go df.workerHashes(result, q)
+    go df.workerHashes(result, hash, q)
   }
   return result
 }
@@ -396,6 +410,7 @@ func (df *DataFrame) DetachedView(columns ...string) *DataFrame {
   result := df.View()
   result.Unshare(columns...)
   result.debugPrint("DetachedView() returns")
+  result.debugValidate("DetachedView()")
   return result
 }
 
@@ -409,6 +424,7 @@ func (df *DataFrame) DetachedView(columns ...string) *DataFrame {
 func (df *DataFrame) View() *DataFrame {
   result := *df
   result.sharedMaps = true
+  result.frozenMaps = false
 
   return &result
 }
@@ -426,5 +442,6 @@ func (df *DataFrame) ResetIndexView() *DataFrame {
   copy(result.indices, df.indices)
   sort.Ints(result.indices)
 
+  result.debugValidate("ResetIndexView()")
   return result
 }
@@ -0,0 +1,175 @@
+package drift
+
+import (
+  "fmt"
+  "math"
+  "sort"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+  "gonum.org/v1/gonum/stat/distuv"
+)
+
+// smoothing of the proportions to avoid divisions by zero
+const epsilon = 1e-4
+
+// CompareOptions specifies when a column is flagged as drifted.
+// A column is flagged if at least one of the thresholds is exceeded.
+type CompareOptions struct {
+  // Population Stability Index above which a column is flagged. Default: 0.2
+  PSIThreshold    float64
+  // Kolmogorov-Smirnov statistic above which a float column is flagged.
+  // Default: 0.1
+  KSThreshold     float64
+  // P-value of the chi-square test below which a column is flagged.
+  // Default: 0.01
+  PValueThreshold float64
+}
+
+// Compare computes the drift scores of the live data with respect to the
+// reference data.
+// It returns a dataframe with one row per profiled column and the following
+// columns:
+//  "column": name of the profiled column
+//  "kind": "numerical" or "categorical"
+//  "psi": Population Stability Index
+//  "ks": Kolmogorov-Smirnov statistic, NaN for categorical columns
+//  "chi2": chi-square statistic of the bins or categories
+//  "p_value": p-value of the chi-square test
+//  "missing_rate": proportion of missing values in the live data
+//  "drifted": whether the column is flagged as drifted
+// Missing values are not taken into account by the scores.
+// It returns an error if a profiled column is missing from the live data or is
+// of a different kind.
+func (profile *Profile) Compare(live *dataframe.DataFrame, options CompareOptions) (*dataframe.DataFrame, error) {
+  if options.PSIThreshold <= 0 {
+    options.PSIThreshold = 0.2
+  }
+  if options.KSThreshold <= 0 {
+    options.KSThreshold = 0.1
+  }
+  if options.PValueThreshold <= 0 {
+    options.PValueThreshold = 0.01
+  }
+  // check the columns
+  floatCols := live.FloatHeader().NameSet()
+  categoricalCols := live.IntHeader().And(live.BoolHeader(), live.StringHeader()).NameSet()
+  for _, c := range profile.Columns {
+    if c.Kind == Numerical && !floatCols[c.Name] {
+      return nil, fmt.Errorf("numerical column %s is not a float column of the live data", c.Name)
+    }
+    if c.Kind == Categorical && !categoricalCols[c.Name] {
+      return nil, fmt.Errorf("categorical column %s is not an int, bool or string column of the live data", c.Name)
+    }
+  }
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  for _, c := range profile.Columns {
+    var frequencies []float64
+    var numValues, missing int
+    ks := math.NaN()
+    if c.Kind == Numerical {
+      var values []float64
+      values, missing = nonMissingFloats(live, c.Name)
+      numValues = len(values)
+      frequencies = histogram(values, c.Edges)
+      if len(c.Quantiles) > 0 {
+        ks = c.ksStatistic(values)
+      }
+    } else {
+      var values []string
+      values, missing = nonMissingCategories(live, c.Name)
+      numValues = len(values)
+      frequencies = categoryFrequencies(values, c.Categories)
+    }
+    psi := math.NaN()
+    chi2 := math.NaN()
+    pValue := math.NaN()
+    if numValues > 0 && len(c.Frequencies) > 0 {
+      psi = populationStabilityIndex(c.Frequencies, frequencies)
+      chi2, pValue = chiSquareTest(c.Frequencies, frequencies, numValues)
+    }
+    missingRate := 0.0
+    if live.NumRows() > 0 {
+      missingRate = float64(missing) / float64(live.NumRows())
+    }
+    // comparisons with NaN are false
+    drifted := psi > options.PSIThreshold || ks > options.KSThreshold ||
+               pValue < options.PValueThreshold
+    builder.AddStrings("column", c.Name)
+    builder.AddStrings("kind", c.Kind)
+    builder.AddFloats("psi", psi)
+    builder.AddFloats("ks", ks)
+    builder.AddFloats("chi2", chi2)
+    builder.AddFloats("p_value", pValue)
+    builder.AddFloats("missing_rate", missingRate)
+    builder.AddBools("drifted", drifted)
+  }
+  return builder.ToDataFrame(), nil
+}
+
+// DriftedColumns returns the names of the columns flagged by Compare.
+// It returns nil if the report is empty, e.g. if the profile has no columns.
+func DriftedColumns(report *dataframe.DataFrame) []string {
+  if report.NumRows() == 0 {
+    return nil
+  }
+  drifted := report.Bools("drifted")
+  columns := report.Strings("column")
+  var result []string
+  for i := 0; i < drifted.Size(); i++ {
+    if drifted.Get(i) {
+      result = append(result, columns.Get(i))
+    }
+  }
+  sort.Strings(result)
+  return result
+}
+
+// populationStabilityIndex computes sum((live - ref) * ln(live / ref)).
+func populationStabilityIndex(reference []float64, live []float64) float64 {
+  result := 0.0
+  for k := range reference {
+    r := reference[k] + epsilon
+    l := live[k] + epsilon
+    result += (l - r) * math.Log(l / r)
+  }
+  return result
+}
+
+// chiSquareTest compares the observed counts of the live data with the counts
+// expected from the reference proportions.
+func chiSquareTest(reference []float64, live []float64, n int) (float64, float64) {
+  chi2 := 0.0
+  bins := 0
+  for k := range reference {
+    if reference[k] == 0 && live[k] == 0 {
+      continue  // e.g. empty bucket of the other categories
+    }
+    bins++
+    expected := (reference[k] + epsilon) * float64(n)
+    diff := live[k] * float64(n) - expected
+    chi2 += diff * diff / expected
+  }
+  if bins < 2 {
+    return 0, 1
+  }
+  dist := distuv.ChiSquared{K: float64(bins - 1)}
+  return chi2, dist.Survival(chi2)
+}
+
+// ksStatistic computes the largest distance between the cumulative
+// distribution functions of the reference data and the live values.
+func (profile *ColumnProfile) ksStatistic(values []float64) float64 {
+  if len(values) == 0 {
+    return math.NaN()
+  }
+  sorted := append([]float64{}, values...)
+  sort.Float64s(sorted)
+  n := float64(len(sorted))
+  result := 0.0
+  for i, v := range sorted {
+    ref := profile.cdf(v)
+    below := float64(i) / n     // live CDF just before v
+    above := float64(i + 1) / n // live CDF at v
+    result = math.Max(result, math.Max(math.Abs(above - ref), math.Abs(below - ref)))
+  }
+  return result
+}
@@ -0,0 +1,251 @@
+package drift
+
+import (
+  "fmt"
+  "math"
+  "sort"
+  "strconv"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+  "github.com/rom1mouret/ml-essentials/utils"
+  "gonum.org/v1/gonum/stat"
+)
+
+// Kinds of ColumnProfile
+const(
+  Numerical   = "numerical"
+  Categorical = "categorical"
+)
+
+// name of the bucket of the categories that are not tracked by the profile
+const otherCategory = "_other"
+
+// number of quantiles used to approximate the reference distribution
+const numQuantiles = 101
+
+// ProfileOptions specifies how the reference data is summarized.
+type ProfileOptions struct {
+  // Number of bins of the histograms of float columns. The bins are delimited
+  // by quantiles of the reference data. Default: 10
+  Bins          int
+  // Maximum number of categories tracked per int, bool or string column. The
+  // least frequent categories are merged into a single bucket. Default: 50
+  MaxCategories int
+  // Columns to profile. Default: all the float, int, bool and string columns.
+  Columns       []string
+}
+
+// ColumnProfile holds the statistics of a reference column.
+type ColumnProfile struct {
+  Name        string
+  // Numerical for float columns, Categorical for int, bool and string columns.
+  Kind        string
+  // Inner edges of the histogram of numerical columns.
+  Edges       []float64 `json:",omitempty"`
+  // Quantiles of numerical columns, from 0% to 100% by step of 1%.
+  Quantiles   []float64 `json:",omitempty"`
+  // Categories of categorical columns. Ints and bools are formatted as
+  // strings. The last category is the bucket of the other categories.
+  Categories  []string  `json:",omitempty"`
+  // Proportion of non-missing values in each bin or category.
+  Frequencies []float64
+  // Proportion of missing values.
+  MissingRate float64
+  // Number of rows of the reference data.
+  Count       int
+}
+
+// Profile summarizes a reference dataframe, typically the training data, in
+// order to detect drifts in the data seen in production.
+// It is json-serializable.
+type Profile struct {
+  Columns []ColumnProfile
+}
+
+// NewProfile computes the statistics of the reference dataframe.
+// It will panic if one of the given columns doesn't exist or is an object
+// column.
+func NewProfile(reference *dataframe.DataFrame, options ProfileOptions) *Profile {
+  if options.Bins <= 0 {
+    options.Bins = 10
+  }
+  if options.MaxCategories <= 0 {
+    options.MaxCategories = 50
+  }
+  columns := options.Columns
+  if len(columns) == 0 {
+    columns = reference.Header().Except(reference.ObjectHeader().Except(
+      reference.StringHeader().NameList()...).NameList()...).NameList()
+  }
+  columns = append([]string{}, columns...)
+  sort.Strings(columns)
+
+  result := &Profile{Columns: make([]ColumnProfile, len(columns))}
+  floatCols := reference.FloatHeader().NameSet()
+  for k, col := range columns {
+    if floatCols[col] {
+      result.Columns[k] = numericalProfile(reference, col, options.Bins)
+    } else {
+      result.Columns[k] = categoricalProfile(reference, col, options.MaxCategories)
+    }
+  }
+  return result
+}
+
+func numericalProfile(df *dataframe.DataFrame, col string, bins int) ColumnProfile {
+  values, missing := nonMissingFloats(df, col)
+  sort.Float64s(values)
+  profile := ColumnProfile{Name: col, Kind: Numerical, Count: df.NumRows()}
+  if df.NumRows() > 0 {
+    profile.MissingRate = float64(missing) / float64(df.NumRows())
+  }
+  if len(values) == 0 {
+    return profile
+  }
+  profile.Quantiles = make([]float64, numQuantiles)
+  for k := range profile.Quantiles {
+    p := float64(k) / float64(numQuantiles - 1)
+    profile.Quantiles[k] = stat.Quantile(p, stat.LinInterp, values, nil)
+  }
+  // inner edges, without duplicates
+  for b := 1; b < bins; b++ {
+    edge := stat.Quantile(float64(b) / float64(bins), stat.LinInterp, values, nil)
+    n := len(profile.Edges)
+    if n == 0 || edge > profile.Edges[n-1] {
+      profile.Edges = append(profile.Edges, edge)
+    }
+  }
+  profile.Frequencies = histogram(values, profile.Edges)
+
+  return profile
+}
+
+func categoricalProfile(df *dataframe.DataFrame, col string, maxCategories int) ColumnProfile {
+  values, missing := nonMissingCategories(df, col)
+  profile := ColumnProfile{Name: col, Kind: Categorical, Count: df.NumRows()}
+  if df.NumRows() > 0 {
+    profile.MissingRate = float64(missing) / float64(df.NumRows())
+  }
+  counts := make(map[string]int)
+  for _, v := range values {
+    counts[v]++
+  }
+  // most frequent categories first, ties broken by name
+  categories := utils.StringSetKeys(utils.ToStringSet(values))
+  sort.Slice(categories, func(i, j int) bool {
+    ci, cj := counts[categories[i]], counts[categories[j]]
+    return ci > cj || (ci == cj && categories[i] < categories[j])
+  })
+  if len(categories) > maxCategories {
+    categories = categories[:maxCategories]
+  }
+  profile.Categories = append(categories, otherCategory)
+  profile.Frequencies = categoryFrequencies(values, profile.Categories)
+
+  return profile
+}
+
+// nonMissingFloats returns the non-NaN values of a float column and the number
+// of NaNs.
+func nonMissingFloats(df *dataframe.DataFrame, col string) ([]float64, int) {
+  access := df.Floats(col)
+  result := make([]float64, 0, access.Size())
+  for i := 0; i < access.Size(); i++ {
+    if v := access.Get(i); !math.IsNaN(v) {
+      result = append(result, v)
+    }
+  }
+  return result, access.Size() - len(result)
+}
+
+// nonMissingCategories returns the non-missing values of an int, bool or
+// string column formatted as strings, and the number of missing values.
+func nonMissingCategories(df *dataframe.DataFrame, col string) ([]string, int) {
+  result := make([]string, 0, df.NumRows())
+  if df.IntHeader().NameSet()[col] {
+    access := df.Ints(col)
+    for i := 0; i < access.Size(); i++ {
+      if v := access.Get(i); v != -1 {
+        result = append(result, strconv.Itoa(v))
+      }
+    }
+  } else if df.BoolHeader().NameSet()[col] {
+    access := df.Bools(col)
+    for i := 0; i < access.Size(); i++ {
+      result = append(result, strconv.FormatBool(access.Get(i)))
+    }
+  } else if df.StringHeader().NameSet()[col] {
+    access := df.Objects(col)
+    for i := 0; i < access.Size(); i++ {
+      if v := access.Get(i); v != nil {
+        result = append(result, v.(string))
+      }
+    }
+  } else {
+    panic(fmt.Sprintf("column %s is not a float, int, bool or string column", col))
+  }
+  return result, df.NumRows() - len(result)
+}
+
+// histogram returns the proportion of values in each bin delimited by edges.
+func histogram(values []float64, edges []float64) []float64 {
+  result := make([]float64, len(edges) + 1)
+  for _, v := range values {
+    result[sort.SearchFloat64s(edges, v)]++
+  }
+  normalize(result)
+  return result
+}
+
+// categoryFrequencies returns the proportion of values in each category. The
+// last category is the bucket of the values that are not in the other
+// categories.
+func categoryFrequencies(values []string, categories []string) []float64 {
+  positions := make(map[string]int)
+  for k, category := range categories[:len(categories)-1] {
+    positions[category] = k
+  }
+  result := make([]float64, len(categories))
+  for _, v := range values {
+    if k, ok := positions[v]; ok {
+      result[k]++
+    } else {
+      result[len(result)-1]++
+    }
+  }
+  normalize(result)
+  return result
+}
+
+func normalize(counts []float64) {
+  total := 0.0
+  for _, c := range counts {
+    total += c
+  }
+  if total == 0 {
+    return
+  }
+  for k := range counts {
+    counts[k] /= total
+  }
+}
+
+// cdf approximates the reference cumulative distribution function at x by
+// interpolating the quantiles.
+func (profile *ColumnProfile) cdf(x float64) float64 {
+  q := profile.Quantiles
+  n := len(q)
+  if x < q[0] {
+    return 0
+  }
+  if x >= q[n-1] {
+    return 1
+  }
+  // first quantile strictly above x
+  k := sort.Search(n, func(i int) bool { return q[i] > x })
+  lo, hi := q[k-1], q[k]
+  p := float64(k - 1)
+  if hi > lo {
+    p += (x - lo) / (hi - lo)
+  }
+  return p / float64(n - 1)
+}
@@ -0,0 +1,60 @@
+package drift
+
+import (
+  "testing"
+  "math/rand"
+  "encoding/json"
+  "github.com/rom1mouret/ml-essentials/utils"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+)
+
+func driftTestData(n int, shift float64, cities []string) *dataframe.DataFrame {
+  b := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  for i := 0; i < n; i++ {
+    b.AddFloats("height", rand.NormFloat64() + shift)
+    b.AddInts("level", rand.Intn(3))
+    b.AddStrings("city", cities[rand.Intn(len(cities))])
+  }
+  return b.ToDataFrame()
+}
+
+func TestNoDrift(t *testing.T) {
+  rand.Seed(1)
+  cities := []string{"paris", "tokyo", "lima"}
+  profile := NewProfile(driftTestData(10000, 0, cities), ProfileOptions{})
+  utils.AssertIntEquals("num columns", len(profile.Columns), 3, t)
+  utils.AssertIntEquals("num bins", len(profile.Columns[1].Frequencies), 10, t)
+
+  report, err := profile.Compare(driftTestData(2000, 0, cities), CompareOptions{})
+  utils.AssertNoError(err, t)
+  utils.AssertIntEquals("num rows", report.NumRows(), 3, t)
+  utils.AssertIntEquals("drifted", len(DriftedColumns(report)), 0, t)
+
+  // empty profile
+  b := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  empty := NewProfile(b.ToDataFrame(), ProfileOptions{})
+  report, err = empty.Compare(driftTestData(10, 0, cities), CompareOptions{})
+  utils.AssertNoError(err, t)
+  utils.AssertIntEquals("empty report", len(DriftedColumns(report)), 0, t)
+}
+
+func TestDrift(t *testing.T) {
+  rand.Seed(2)
+  profile := NewProfile(driftTestData(10000, 0, []string{"paris", "tokyo"}), ProfileOptions{})
+
+  // json round trip
+  encoded, err := json.Marshal(profile)
+  utils.AssertNoError(err, t)
+  decoded := &Profile{}
+  utils.AssertNoError(json.Unmarshal(encoded, decoded), t)
+
+  live := driftTestData(2000, 1, []string{"paris", "tokyo", "lima"})
+  report, err := decoded.Compare(live, CompareOptions{})
+  utils.AssertNoError(err, t)
+  utils.AssertStringSliceEquals("drifted", DriftedColumns(report), []string{"city", "height"}, true, t)
+  utils.AssertTrue("ks", report.Floats("ks").Get(1) > 0.3, t)
+
+  // missing column
+  _, err = profile.Compare(live.ColumnView("height"), CompareOptions{})
+  utils.AssertTrue("missing column", err != nil, t)
+}
@@ -20,15 +20,28 @@ type AutoPreprocOptions struct {
 // AutoPreprocessor is a structure that does most of the basic preprocessing for
 // you. It encapsulates a string-to-int encoder, a category encoder, an imputer
 // and a scaler (including centering).
+// It also records the schema of the training data, which is enforced by
+// TransformView.
 // It is json-serializable.
 type AutoPreprocessor struct {
   StringToInt *HashEncoder
   IntToBool   *OneHotEncoder
   Imputer     *FloatImputer
   Scaler      *Scaler
+  Schema      *dataframe.Schema
   options     AutoPreprocOptions
 }
 
+// columns of the wrong type are converted, other discrepancies are left to the
+// preprocessing components, except for missing columns
+var autoConformOptions = dataframe.ConformOptions{
+  MissingColumns: dataframe.RejectDiscrepancy,
+  ExtraColumns: dataframe.IgnoreDiscrepancy,
+  TypeMismatches: dataframe.FixDiscrepancy,
+  OutOfRange: dataframe.IgnoreDiscrepancy,
+  UnknownCategories: dataframe.IgnoreDiscrepancy,
+}
+
 // NewAutoPreprocessor allocates a new untrained AutoPreprocessor
 func NewAutoPreprocessor(opt AutoPreprocOptions) *AutoPreprocessor {
   result := new(AutoPreprocessor)
@@ -66,6 +79,8 @@ func (preproc *AutoPreprocessor) FitTransform(df *dataframe.DataFrame) *datafram
 
 func (preproc *AutoPreprocessor) fit(df *dataframe.DataFrame, transform bool) (*dataframe.DataFrame, error) {
   opt := preproc.options
+  inputCols := df.Header().Except(opt.Exclude...).NameList()
+  preproc.Schema = dataframe.CaptureSchema(df.ColumnView(inputCols...), dataframe.SchemaOptions{})
   floatCols := df.FloatHeader().Except(opt.Exclude...).NameList()
   if opt.Imputing || opt.Scaling {
     start := time.Now()
@@ -94,7 +109,7 @@ func (preproc *AutoPreprocessor) fit(df *dataframe.DataFrame, transform bool) (*
     if err != nil {
       return nil, err
     }
-    categorical = preproc.StringToInt.TransformedColumns()
+    categorical = preproc.StringToInt.CategoricalColumns
     if opt.Verbose {
       log.Printf("running Hash Encoder took %s with cpu=%d", time.Since(start), df.ActualMaxCPU())
     }
@@ -143,7 +158,18 @@ func (preproc *AutoPreprocessor) fit(df *dataframe.DataFrame, transform bool) (*
 // dataframe and returns a preprocessed dataframe.
 // Because it returns a view, columns that don't require preprocessing will
 // share their data with the columns of the original dataframe.
+// Columns whose type differs from the training data are converted if the
+// conversion is lossless, e.g. an int column parsed as a float column.
+// It returns a *dataframe.ConformError if a column is missing or cannot be
+// converted.
 func (preproc *AutoPreprocessor) TransformView(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+  if preproc.Schema != nil {
+    conformed, err := preproc.Schema.Conform(df, autoConformOptions)
+    if err != nil {
+      return nil, err
+    }
+    df = conformed
+  }
   // prepare transformations in place
   if preproc.Imputer != nil {
     df = df.DetachedView(preproc.Imputer.TransformedColumns()...)
@@ -0,0 +1,265 @@
+package preprocessing
+
+import (
+  "fmt"
+  "math"
+  "sort"
+  "github.com/rom1mouret/ml-essentials/utils"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+)
+
+type BinningStrategy int
+
+const (
+  // bins of equal width
+  UniformBins BinningStrategy = iota
+  // bins with the same number of training values
+  QuantileBins
+  // bins centered on the clusters found by 1-dimensional k-means
+  KMeansBins
+)
+
+type DiscretizerOptions struct {
+  Strategy BinningStrategy
+  // Maximum number of bins per column. Default: 5
+  // Columns may end up with fewer bins, e.g. if many values are identical.
+  NumBins int
+  // If true, the bins are output as bool columns named <col>_bin<N>.
+  // Otherwise, they are output as int columns named <col>_bin.
+  OneHot bool
+  // Unless KeepUsedColumns is true, the float columns are removed from the
+  // transformed dataframe.
+  KeepUsedColumns bool
+}
+
+// Discretizer is a json-serializable structure that divides float columns
+// into bins.
+// Edges maps each column to the sorted edges of its bins, from the minimum to
+// the maximum training value. Bin k spans [Edges[k], Edges[k+1]).
+// Values outside the training range fall into the first or the last bin.
+// NaN values are converted to -1, the int missing value marker, or to false
+// in every bool column if OneHot is enabled.
+type Discretizer struct {
+  Edges   map[string][]float64
+  Options DiscretizerOptions
+}
+
+const defaultNumBins = 5
+
+// NewDiscretizer allocates a new Discretizer.
+func NewDiscretizer(opt DiscretizerOptions) *Discretizer {
+  discretizer := new(Discretizer)
+  if opt.NumBins <= 0 {
+    opt.NumBins = defaultNumBins
+  }
+  discretizer.Options = opt
+  return discretizer
+}
+
+// quantile returns the q-th quantile of the sorted values.
+func quantile(sorted []float64, q float64) float64 {
+  pos := q * float64(len(sorted) - 1)
+  lower := int(math.Floor(pos))
+  if lower >= len(sorted) - 1 {
+    return sorted[len(sorted) - 1]
+  }
+  frac := pos - float64(lower)
+  return sorted[lower] * (1 - frac) + sorted[lower + 1] * frac
+}
+
+// kmeansCenters runs Lloyd's algorithm on the sorted values and returns the
+// sorted centers of the clusters.
+func kmeansCenters(sorted []float64, k int) []float64 {
+  min := sorted[0]
+  max := sorted[len(sorted) - 1]
+  // initialize the centers in the middle of uniform bins
+  centers := make([]float64, k)
+  for c := range centers {
+    centers[c] = min + (float64(c) + 0.5) * (max - min) / float64(k)
+  }
+  for iter := 0; iter < 100; iter++ {
+    // values are sorted so the clusters are delimited by the midpoints
+    sums := make([]float64, k)
+    counts := make([]int, k)
+    c := 0
+    for _, v := range sorted {
+      for c < k - 1 && v > (centers[c] + centers[c + 1]) / 2 {
+        c++
+      }
+      sums[c] += v
+      counts[c]++
+    }
+    moved := false
+    for c := range centers {
+      if counts[c] > 0 {
+        center := sums[c] / float64(counts[c])
+        if center != centers[c] {
+          moved = true
+          centers[c] = center
+        }
+      }
+    }
+    if !moved {
+      break
+    }
+    sort.Float64s(centers)
+  }
+  return centers
+}
+
+// computeEdges returns the edges of the bins for the given values.
+func computeEdges(values []float64, opt DiscretizerOptions) []float64 {
+  sorted := make([]float64, 0, len(values))
+  for _, v := range values {
+    if !math.IsNaN(v) {
+      sorted = append(sorted, v)
+    }
+  }
+  if len(sorted) == 0 {
+    return nil
+  }
+  sort.Float64s(sorted)
+  min := sorted[0]
+  max := sorted[len(sorted) - 1]
+  n := opt.NumBins
+  if n <= 0 {
+    n = defaultNumBins  // e.g. Discretizer not created with NewDiscretizer
+  }
+  edges := make([]float64, n + 1)
+  switch opt.Strategy {
+  case QuantileBins:
+    for k := range edges {
+      edges[k] = quantile(sorted, float64(k) / float64(n))
+    }
+  case KMeansBins:
+    centers := kmeansCenters(sorted, n)
+    for k := 1; k < n; k++ {
+      edges[k] = (centers[k - 1] + centers[k]) / 2
+    }
+  default:
+    for k := range edges {
+      edges[k] = min + float64(k) * (max - min) / float64(n)
+    }
+  }
+  edges[0] = min
+  edges[n] = max
+  // remove the duplicate edges, i.e. the empty bins
+  result := edges[:1]
+  for _, edge := range edges[1:] {
+    if edge > result[len(result) - 1] {
+      result = append(result, edge)
+    }
+  }
+  if len(result) == 1 {
+    result = append(result, max)  // constant column
+  }
+  return result
+}
+
+func (discretizer *Discretizer) workerFits(df *dataframe.DataFrame, q utils.StringQ) {
+  for col := q.Next(); len(col) > 0; col = q.Next() {
+    edges := computeEdges(df.Floats(col).VecDense().RawVector().Data, discretizer.Options)
+    q.Notify(utils.ProcessedJob{Key: col, Result: edges})
+  }
+}
+
+// Fit implements PreprocTraining and Transform interfaces.
+// It computes the bins of every float column.
+func (discretizer *Discretizer) Fit(df *dataframe.DataFrame) error {
+  discretizer.Edges = make(map[string][]float64)
+  q := df.CreateColumnQueue(df.FloatHeader().NameList())
+  for i := 0; i < q.Workers; i++ {
+    go discretizer.workerFits(df, q)
+  }
+  for _, job := range q.Results() {
+    discretizer.Edges[job.Key] = job.Result.([]float64)
+  }
+  return nil
+}
+
+// NumBins returns the number of bins of the given column.
+func (discretizer *Discretizer) NumBins(col string) int {
+  if n := len(discretizer.Edges[col]) - 1; n > 0 {
+    return n
+  }
+  return 1
+}
+
+// Bin returns the bin of the given value, or -1 if the value is NaN.
+func (discretizer *Discretizer) Bin(col string, value float64) int {
+  if math.IsNaN(value) {
+    return -1
+  }
+  edges := discretizer.Edges[col]
+  if len(edges) <= 2 {
+    return 0
+  }
+  inner := edges[1:len(edges)-1]
+  return sort.Search(len(inner), func(k int) bool { return inner[k] > value })
+}
+
+func (discretizer *Discretizer) binColumn(col string, k int) string {
+  return fmt.Sprintf("%s_bin%d", col, k)
+}
+
+func (discretizer *Discretizer) workerTransforms(input *dataframe.DataFrame, output *dataframe.DataFrame, q utils.StringQ) {
+  for col := q.Next(); len(col) > 0; col = q.Next() {
+    values := input.Floats(col)
+    if discretizer.Options.OneHot {
+      accesses := make([]dataframe.BoolAccess, discretizer.NumBins(col))
+      for k := range accesses {
+        accesses[k] = output.Bools(discretizer.binColumn(col, k))
+      }
+      for i := 0; i < values.Size(); i++ {
+        if bin := discretizer.Bin(col, values.Get(i)); bin >= 0 {
+          accesses[bin].Set(i, true)
+        }
+      }
+    } else {
+      codes := output.Ints(col + "_bin")
+      for i := 0; i < values.Size(); i++ {
+        codes.Set(i, discretizer.Bin(col, values.Get(i)))
+      }
+    }
+    q.Notify(utils.ProcessedJob{Key: col})
+  }
+}
+
+// TransformView implements PreprocTraining and Transform interfaces.
+// This function is multi-threaded.
+func (discretizer *Discretizer) TransformView(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+  result := df.View()
+  columns := discretizer.TransformedColumns()
+  if len(columns) == 0 {
+    return result, nil
+  }
+  // allocate the new columns
+  for _, col := range columns {
+    if discretizer.Options.OneHot {
+      for k := 0; k < discretizer.NumBins(col); k++ {
+        result.AllocBools(discretizer.binColumn(col, k))
+      }
+    } else {
+      result.AllocInts(col + "_bin")
+    }
+  }
+  q := df.CreateColumnQueue(columns)
+  for i := 0; i < q.Workers; i++ {
+    go discretizer.workerTransforms(df, result, q)
+  }
+  q.Wait()
+  if !discretizer.Options.KeepUsedColumns {
+    result.Drop(columns...)
+  }
+  return result, nil
+}
+
+// TransformedColumns implements PreprocTraining and Transform interfaces.
+func (discretizer *Discretizer) TransformedColumns() []string {
+  result := make([]string, 0, len(discretizer.Edges))
+  for col := range discretizer.Edges {
+    result = append(result, col)
+  }
+  sort.Strings(result)
+  return result
+}
@@ -0,0 +1,73 @@
+package preprocessing
+
+import (
+  "math"
+  "testing"
+  "encoding/json"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+  u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func TestDiscretizerStrategies(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddFloats("skewed", 0, 1, 1.5, 2, 2.5, 3, 100, 101, math.NaN())
+  df := builder.ToDataFrame()
+
+  uniform := NewDiscretizer(DiscretizerOptions{NumBins: 2})
+  uniform.Fit(df)
+  u.AssertFloatSliceEquals("uniform", uniform.Edges["skewed"], []float64{0, 50.5, 101}, t)
+  u.AssertIntEquals("uniform bin", uniform.Bin("skewed", 3), 0, t)
+
+  quantiles := NewDiscretizer(DiscretizerOptions{Strategy: QuantileBins, NumBins: 2})
+  quantiles.Fit(df)
+  u.AssertFloatSliceEquals("quantile", quantiles.Edges["skewed"], []float64{0, 2.25, 101}, t)
+  u.AssertIntEquals("quantile bin", quantiles.Bin("skewed", 3), 1, t)
+  u.AssertIntEquals("edge", quantiles.Bin("skewed", 2.25), 1, t)
+  u.AssertIntEquals("below min", quantiles.Bin("skewed", -10), 0, t)
+
+  kmeans := NewDiscretizer(DiscretizerOptions{Strategy: KMeansBins, NumBins: 2})
+  kmeans.Fit(df)
+  u.AssertIntEquals("kmeans num bins", kmeans.NumBins("skewed"), 2, t)
+  u.AssertIntEquals("kmeans low", kmeans.Bin("skewed", 3), 0, t)
+  u.AssertIntEquals("kmeans high", kmeans.Bin("skewed", 100), 1, t)
+
+  // default number of bins without NewDiscretizer
+  for _, strategy := range []BinningStrategy{UniformBins, QuantileBins, KMeansBins} {
+    discretizer := &Discretizer{Options: DiscretizerOptions{Strategy: strategy}}
+    discretizer.Fit(df)
+    u.AssertTrue("default bins", discretizer.NumBins("skewed") > 1, t)
+    u.AssertIntEquals("max", discretizer.Bin("skewed", 101), discretizer.NumBins("skewed") - 1, t)
+  }
+}
+
+func TestDiscretizerTransform(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddFloats("height", 1, 2, 3, 4, math.NaN())
+  builder.AddFloats("constant", 7, 7, 7, 7, 7)
+  df := builder.ToDataFrame()
+  discretizer := NewDiscretizer(DiscretizerOptions{NumBins: 3})
+  discretizer.Fit(df)
+
+  // serialization
+  serialized, _ := json.Marshal(discretizer)
+  discretizer = &Discretizer{}
+  json.Unmarshal(serialized, &discretizer)
+
+  result, err := discretizer.TransformView(df)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  u.AssertIntEquals("num floats", result.FloatHeader().Num(), 0, t)
+  codes := result.Ints("height_bin")
+  u.AssertIntEquals("first", codes.Get(0), 0, t)
+  u.AssertIntEquals("last", codes.Get(3), 2, t)
+  u.AssertIntEquals("missing", codes.Get(4), -1, t)
+  u.AssertIntEquals("constant", result.Ints("constant_bin").Get(0), 0, t)
+
+  discretizer.Options.OneHot = true
+  result, err = discretizer.TransformView(df)
+  u.AssertNoError(err, t)
+  u.AssertIntEquals("num bools", result.BoolHeader().Num(), 4, t)
+  u.AssertTrue("one-hot", result.Bools("height_bin2").Get(3), t)
+  u.AssertFalse("one-hot missing", result.Bools("height_bin0").Get(4), t)
+}
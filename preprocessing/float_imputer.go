@@ -91,7 +91,11 @@ func (imputer *FloatImputer) Fit(df *dataframe.DataFrame) error {
 }
 
 // TransformInplace implements PreprocTraining interface and InplaceTransform.
+// It returns an error if df is frozen, in which case df is not altered.
 func (imputer *FloatImputer) TransformInplace(df *dataframe.DataFrame) error {
+  if err := df.CheckWritable(mapKeys(imputer.Fallback)...); err != nil {
+    return err
+  }
   // TODO: multithread this?
   for col, fallback := range imputer.Fallback {
     access := df.Floats(col)
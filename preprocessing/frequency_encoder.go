@@ -0,0 +1,183 @@
+package preprocessing
+
+import (
+  "fmt"
+  "math"
+  "sort"
+  "github.com/rom1mouret/ml-essentials/utils"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+)
+
+type FrequencyPolicy int
+
+const (
+  // The frequency observed in the training data. Missing values are counted
+  // as a category of their own, whereas unknown categories get zero since
+  // they were never seen.
+  TrainingFrequency FrequencyPolicy = iota
+  // self-explanatory
+  ZeroFrequency
+  NaNFrequency
+  FrequencyError
+)
+
+type FrequencyEncoderOptions struct {
+  // If true, the frequencies are divided by the number of training rows.
+  // Otherwise, they are raw counts.
+  Normalize       bool
+  MissingPolicy   FrequencyPolicy
+  UnknownPolicy   FrequencyPolicy
+  // Unless KeepUsedColumns is true, the categorical columns are removed from
+  // the transformed dataframe.
+  KeepUsedColumns bool
+}
+
+// FrequencyEncoder is a json-serializable structure that replaces int and
+// string categories with their frequency in the training data.
+// The frequencies are written to float columns named <col>_freq.
+// Frequencies maps each categorical column to the frequencies of its
+// categories. Int categories are converted to strings so as to be
+// json-serializable.
+type FrequencyEncoder struct {
+  CategoricalColumns []string
+  Frequencies        map[string]map[string]float64
+  MissingFrequencies map[string]float64
+  Options            FrequencyEncoderOptions
+}
+
+// NewFrequencyEncoder allocates a new FrequencyEncoder.
+func NewFrequencyEncoder(opt FrequencyEncoderOptions) *FrequencyEncoder {
+  encoder := new(FrequencyEncoder)
+  encoder.Options = opt
+  return encoder
+}
+
+type frequencyFitting struct {
+  frequencies map[string]float64
+  missing     float64
+}
+
+func (encoder *FrequencyEncoder) workerFits(df *dataframe.DataFrame, q utils.StringQ) {
+  for col := q.Next(); len(col) > 0; col = q.Next() {
+    result := frequencyFitting{frequencies: make(map[string]float64)}
+    keys := categoryKeys(df, col)
+    for i := 0; i < df.NumRows(); i++ {
+      if key, ok := keys(i); ok {
+        result.frequencies[key]++
+      } else {
+        result.missing++
+      }
+    }
+    if encoder.Options.Normalize && df.NumRows() > 0 {
+      n := float64(df.NumRows())
+      for key, count := range result.frequencies {
+        result.frequencies[key] = count / n
+      }
+      result.missing /= n
+    }
+    q.Notify(utils.ProcessedJob{Key: col, Result: &result})
+  }
+}
+
+// Fit implements PreprocTraining and Transform interfaces.
+// It counts the categories of every int and string column.
+func (encoder *FrequencyEncoder) Fit(df *dataframe.DataFrame) error {
+  columns := df.IntHeader().NameList()
+  columns = append(columns, df.StringHeader().NameList()...)
+  sort.Strings(columns)
+  encoder.CategoricalColumns = columns
+  encoder.Frequencies = make(map[string]map[string]float64)
+  encoder.MissingFrequencies = make(map[string]float64)
+  if len(columns) == 0 {
+    return nil
+  }
+  q := df.CreateColumnQueue(columns)
+  for i := 0; i < q.Workers; i++ {
+    go encoder.workerFits(df, q)
+  }
+  for _, job := range q.Results() {
+    result := job.Result.(*frequencyFitting)
+    encoder.Frequencies[job.Key] = result.frequencies
+    encoder.MissingFrequencies[job.Key] = result.missing
+  }
+  return nil
+}
+
+// fallbackFrequency returns the frequency of a missing or unknown category,
+// according to the given policy.
+func fallbackFrequency(policy FrequencyPolicy, trainingFreq float64) float64 {
+  switch policy {
+  case TrainingFrequency:
+    return trainingFreq
+  case NaNFrequency:
+    return math.NaN()
+  }
+  return 0
+}
+
+func (encoder *FrequencyEncoder) workerTransforms(input *dataframe.DataFrame, output *dataframe.DataFrame, q utils.StringQ) {
+  opt := encoder.Options
+  for col := q.Next(); len(col) > 0; col = q.Next() {
+    notif := utils.ProcessedJob{Key: col}
+    frequencies := encoder.Frequencies[col]
+    missingFreq := fallbackFrequency(opt.MissingPolicy, encoder.MissingFrequencies[col])
+    unknownFreq := fallbackFrequency(opt.UnknownPolicy, 0)
+    keys := categoryKeys(input, col)
+    access := output.Floats(col + "_freq")
+    for i := 0; i < access.Size(); i++ {
+      key, ok := keys(i)
+      if !ok {
+        if opt.MissingPolicy == FrequencyError {
+          notif.Error = fmt.Errorf("missing value in column %s", col)
+          break
+        }
+        access.Set(i, missingFreq)
+      } else if freq, ok := frequencies[key]; ok {
+        access.Set(i, freq)
+      } else if opt.UnknownPolicy == FrequencyError {
+        notif.Error = fmt.Errorf("%s: unknown category in column %s", key, col)
+        break
+      } else {
+        access.Set(i, unknownFreq)
+      }
+    }
+    q.Notify(notif)
+  }
+}
+
+// TransformView implements PreprocTraining and Transform interfaces.
+// It returns an error if a categorical column is missing or of the wrong type,
+// or if a missing or unknown category is found and the corresponding policy is
+// FrequencyError.
+// This function is multi-threaded.
+func (encoder *FrequencyEncoder) TransformView(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+  result := df.View()
+  if len(encoder.CategoricalColumns) == 0 {
+    return result, nil
+  }
+  if err := checkCategoricalColumns(df, encoder.CategoricalColumns); err != nil {
+    return nil, err
+  }
+  // allocate the new columns
+  for _, col := range encoder.CategoricalColumns {
+    result.AllocFloats(col + "_freq")
+  }
+  q := df.CreateColumnQueue(encoder.CategoricalColumns)
+  for i := 0; i < q.Workers; i++ {
+    go encoder.workerTransforms(df, result, q)
+  }
+  for _, job := range q.Results() {
+    if job.Error != nil {
+      return nil, job.Error
+    }
+  }
+  if !encoder.Options.KeepUsedColumns {
+    result.Drop(encoder.CategoricalColumns...)
+  }
+  return result, nil
+}
+
+// TransformedColumns implements PreprocTraining and Transform interfaces.
+func (encoder *FrequencyEncoder) TransformedColumns() []string {
+  return encoder.CategoricalColumns
+}
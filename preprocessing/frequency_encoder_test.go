@@ -0,0 +1,80 @@
+package preprocessing
+
+import (
+  "math"
+  "testing"
+  "encoding/json"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+  u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func TestFrequencyEncoder(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddStrings("city", "paris", "tokyo", "tokyo", "")
+  builder.AddInts("size", 3, 3, 3, -1)
+  builder.AddFloats("height", 1, 2, 3, 4)
+  df := builder.ToDataFrame()
+  df.Objects("city").Set(3, nil)
+
+  encoder := NewFrequencyEncoder(FrequencyEncoderOptions{Normalize: true})
+  if !u.AssertNoError(encoder.Fit(df), t) {
+    return
+  }
+  u.AssertStringSliceEquals("columns", encoder.TransformedColumns(), []string{"city", "size"}, true, t)
+
+  // serialization
+  serialized, _ := json.Marshal(encoder)
+  encoder = &FrequencyEncoder{}
+  json.Unmarshal(serialized, &encoder)
+
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddStrings("city", "tokyo", "rome", "")
+  builder.AddInts("size", 3, 4, -1)
+  test := builder.ToDataFrame()
+  test.Objects("city").Set(2, nil)
+  result, err := encoder.TransformView(test)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  u.AssertIntEquals("num strings", result.StringHeader().Num(), 0, t)
+  u.AssertIntEquals("num ints", result.IntHeader().Num(), 0, t)
+  u.AssertFloatSliceEquals("city", floatValues(result.Floats("city_freq")), []float64{0.5, 0, 0.25}, t)
+  u.AssertFloatSliceEquals("size", floatValues(result.Floats("size_freq")), []float64{0.75, 0, 0.25}, t)
+}
+
+func TestFrequencyEncoderPolicies(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  df := builder.AddInts("size", 3, 3, 5).ToDataFrame()
+
+  opt := FrequencyEncoderOptions{MissingPolicy: NaNFrequency, UnknownPolicy: FrequencyError, KeepUsedColumns: true}
+  encoder := NewFrequencyEncoder(opt)
+  encoder.Fit(df)
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  test := builder.AddInts("size", 5, -1, 3).ToDataFrame()
+  result, err := encoder.TransformView(test)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  u.AssertIntEquals("kept", result.Ints("size").Get(0), 5, t)
+  freqs := result.Floats("size_freq")
+  u.AssertFloatEquals("known", freqs.Get(0), 1, t)
+  u.AssertTrue("missing", math.IsNaN(freqs.Get(1)), t)
+  u.AssertFloatEquals("count", freqs.Get(2), 2, t)
+
+  // unknown category
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  test = builder.AddInts("size", 4).ToDataFrame()
+  if _, err := encoder.TransformView(test); err == nil {
+    t.Errorf("unknown category should be reported")
+  }
+
+  // missing and float columns
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  test = builder.AddInts("level", 3).ToDataFrame()
+  _, err = encoder.TransformView(test)
+  u.AssertTrue("missing column", err != nil, t)
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  test = builder.AddFloats("size", 3).ToDataFrame()
+  _, err = encoder.TransformView(test)
+  u.AssertTrue("float column", err != nil, t)
+}
@@ -1,11 +1,28 @@
 package preprocessing
 
 import (
+  "fmt"
+  "strings"
+  "github.com/rom1mouret/ml-essentials/utils"
   "github.com/rom1mouret/ml-essentials/dataframe"
 )
 
 type HashEncoderOptions struct {
-  // nothing yet
+  // Number of buckets, i.e. hashes range from 0 to Buckets-1.
+  // Zero means the whole range of positive ints.
+  Buckets   int
+  // Default: FNV
+  Algorithm utils.HashAlgorithm
+  Seed      uint64
+  // If true, a float column named <col>_sign is created for each hashed
+  // column. It holds +1 or -1 depending on a second hash of the category, or
+  // 0 if the category is missing. Multiplying the one-hot vectors by the sign
+  // prevents collisions from biasing linear models.
+  Signed    bool
+  // Each cross hashes the categories of the given string or int columns
+  // together, into an int column named after the columns joined with "_x_",
+  // e.g. "city_x_size". The inputs of the crosses are left untouched.
+  Crosses   [][]string
 }
 
 // HashEncoder is a json-serializable structure that hashes strings into
@@ -16,13 +33,18 @@ type HashEncoderOptions struct {
 // categorical encoding.
 // Unless the number of categories is really huge, you need not worry about
 // hashing collisions, especially if you run the encoder on a 64-bit system.
+// If Options.Buckets is set, however, collisions are expected. This is known
+// as the hashing trick: https://en.wikipedia.org/wiki/Feature_hashing
 type HashEncoder struct {
   CategoricalColumns []string
+  Options            HashEncoderOptions
 }
 
 // NewHashEncoder allocates a new HashEncoder.
 func NewHashEncoder(opt HashEncoderOptions) *HashEncoder {
-  return new(HashEncoder)
+  encoder := new(HashEncoder)
+  encoder.Options = opt
+  return encoder
 }
 
 // Fit implements PreprocTraining and Transform interfaces.
@@ -31,13 +53,173 @@ func (encoder *HashEncoder) Fit(df *dataframe.DataFrame) error {
   return nil
 }
 
+// maxInt will vary depending on whether the code is compiled on a 32-bit or a
+// 64-bit system.
+const maxInt = uint64(^uint(0) >> 1)
+
+// hash returns the bucket of the given category.
+func (encoder *HashEncoder) hash(data []byte) int {
+  opt := encoder.Options
+  h := utils.Hash(opt.Algorithm, data, opt.Seed)
+  if opt.Buckets > 0 {
+    return int(h % uint64(opt.Buckets))
+  }
+  return int(h % maxInt)
+}
+
+// sign returns +1 or -1, independently of the bucket of the category.
+func (encoder *HashEncoder) sign(data []byte) float64 {
+  opt := encoder.Options
+  if utils.Hash(opt.Algorithm, data, opt.Seed + 1) & 1 == 0 {
+    return 1
+  }
+  return -1
+}
+
+// hashedColumn describes an output column of hashes or signs.
+type hashedColumn struct {
+  inputs []string
+  sign   bool
+}
+
+// extraColumns returns the sign columns and the crosses, indexed by name.
+func (encoder *HashEncoder) extraColumns() map[string]hashedColumn {
+  result := make(map[string]hashedColumn)
+  opt := encoder.Options
+  if opt.Signed {
+    for _, col := range encoder.CategoricalColumns {
+      result[col + "_sign"] = hashedColumn{inputs: []string{col}, sign: true}
+    }
+  }
+  for _, cross := range opt.Crosses {
+    name := strings.Join(cross, "_x_")
+    result[name] = hashedColumn{inputs: cross}
+    if opt.Signed {
+      result[name + "_sign"] = hashedColumn{inputs: cross, sign: true}
+    }
+  }
+  return result
+}
+
+func (encoder *HashEncoder) workerExtras(input *dataframe.DataFrame, output *dataframe.DataFrame, extras map[string]hashedColumn, q utils.StringQ) {
+  for name := q.Next(); len(name) > 0; name = q.Next() {
+    extra := extras[name]
+    keys := make([]func(i int) (string, bool), len(extra.inputs))
+    for k, col := range extra.inputs {
+      keys[k] = categoryKeys(input, col)
+    }
+    var signs dataframe.FloatAccess
+    var hashes dataframe.IntAccess
+    if extra.sign {
+      signs = output.Floats(name)
+    } else {
+      hashes = output.Ints(name)
+    }
+    parts := make([]string, len(keys))
+    var data []byte
+    for i := 0; i < input.NumRows(); i++ {
+      missing := false
+      for k, key := range keys {
+        var ok bool
+        if parts[k], ok = key(i); !ok {
+          missing = true
+          break
+        }
+      }
+      if !missing {
+        data = append(data[:0], strings.Join(parts, "\x00")...)
+      }
+      if extra.sign {
+        val := 0.0
+        if !missing {
+          val = encoder.sign(data)
+        }
+        signs.Set(i, val)
+      } else {
+        val := -1
+        if !missing {
+          val = encoder.hash(data)
+        }
+        hashes.Set(i, val)
+      }
+    }
+    q.Notify(utils.ProcessedJob{Key: name})
+  }
+}
+
+// checkInputs returns an error if a hashed column is not a string column or a
+// cross input is neither an int column nor a string column.
+func (encoder *HashEncoder) checkInputs(df *dataframe.DataFrame) error {
+  stringCols := df.StringHeader().NameSet()
+  intCols := df.IntHeader().NameSet()
+  for _, col := range encoder.CategoricalColumns {
+    if !stringCols[col] {
+      return fmt.Errorf("%s is not a string column", col)
+    }
+  }
+  for _, cross := range encoder.Options.Crosses {
+    for _, col := range cross {
+      if !stringCols[col] && !intCols[col] {
+        return fmt.Errorf("cross input %s is neither an int column nor a string column", col)
+      }
+    }
+  }
+  return nil
+}
+
 // TransformView implements PreprocTraining and Transform interfaces.
+// It returns an error if an input column is missing or of the wrong type.
 // This function is multi-threaded.
 func (encoder *HashEncoder) TransformView(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
-  return df.HashStringsView(encoder.CategoricalColumns...), nil
+  if err := encoder.checkInputs(df); err != nil {
+    return nil, err
+  }
+  result := df.HashStringsFuncView(func(s string) int {
+    return encoder.hash([]byte(s))
+  }, encoder.CategoricalColumns...)
+
+  extras := encoder.extraColumns()
+  if len(extras) == 0 {
+    return result, nil
+  }
+  // allocate the sign columns and the crosses
+  names := make([]string, 0, len(extras))
+  for name, extra := range extras {
+    if extra.sign {
+      result.AllocFloats(name)
+    } else {
+      result.AllocInts(name)
+    }
+    names = append(names, name)
+  }
+  q := df.CreateColumnQueue(names)
+  for i := 0; i < q.Workers; i++ {
+    go encoder.workerExtras(df, result, extras, q)
+  }
+  q.Wait()
+  return result, nil
 }
 
 // TransformedColumns implements PreprocTraining and Transform interfaces.
+// It returns the string columns followed by the inputs of the crosses that
+// are not string columns.
 func (encoder *HashEncoder) TransformedColumns() []string {
-  return encoder.CategoricalColumns
+  if len(encoder.Options.Crosses) == 0 {
+    return encoder.CategoricalColumns
+  }
+  result := make([]string, 0, len(encoder.CategoricalColumns))
+  seen := make(map[string]bool)
+  for _, col := range encoder.CategoricalColumns {
+    result = append(result, col)
+    seen[col] = true
+  }
+  for _, cross := range encoder.Options.Crosses {
+    for _, col := range cross {
+      if !seen[col] {
+        result = append(result, col)
+        seen[col] = true
+      }
+    }
+  }
+  return result
 }
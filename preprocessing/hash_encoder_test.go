@@ -0,0 +1,100 @@
+package preprocessing
+
+import (
+  "testing"
+  "encoding/json"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+  u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func hashTestData() *dataframe.DataFrame {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddStrings("city", "paris", "tokyo", "paris", "", "tokyo")
+  builder.AddInts("size", 3, 3, 3, 4, 4)
+  df := builder.ToDataFrame()
+  df.Objects("city").Set(3, nil)
+  return df
+}
+
+func TestHashEncoderDefault(t *testing.T) {
+  df := hashTestData()
+  encoder := NewHashEncoder(HashEncoderOptions{})
+  encoder.Fit(df)
+  result, err := encoder.TransformView(df)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  // same as the dataframe's default hashing
+  expected := df.HashStringsView("city").Ints("city")
+  hashes := result.Ints("city")
+  for i := 0; i < df.NumRows(); i++ {
+    u.AssertIntEquals("hash", hashes.Get(i), expected.Get(i), t)
+  }
+  u.AssertIntEquals("missing", hashes.Get(3), -1, t)
+  u.AssertIntEquals("num columns", result.NumColumns(), 2, t)
+}
+
+func TestHashEncoderBucketsAndSigns(t *testing.T) {
+  df := hashTestData()
+  opt := HashEncoderOptions{Buckets: 8, Algorithm: u.XXHash, Seed: 42, Signed: true}
+  encoder := NewHashEncoder(opt)
+  encoder.Fit(df)
+
+  // serialization
+  serialized, _ := json.Marshal(encoder)
+  encoder = &HashEncoder{}
+  json.Unmarshal(serialized, &encoder)
+
+  result, err := encoder.TransformView(df)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  hashes := result.Ints("city")
+  signs := result.Floats("city_sign")
+  for i := 0; i < df.NumRows(); i++ {
+    if i == 3 {
+      u.AssertIntEquals("missing", hashes.Get(i), -1, t)
+      u.AssertFloatEquals("missing sign", signs.Get(i), 0, t)
+    } else {
+      u.AssertTrue("bucket", hashes.Get(i) >= 0 && hashes.Get(i) < 8, t)
+      u.AssertTrue("sign", signs.Get(i) == 1 || signs.Get(i) == -1, t)
+    }
+  }
+  u.AssertIntEquals("same bucket", hashes.Get(0), hashes.Get(2), t)
+  u.AssertFloatEquals("same sign", signs.Get(1), signs.Get(4), t)
+}
+
+func TestHashEncoderCrosses(t *testing.T) {
+  df := hashTestData()
+  opt := HashEncoderOptions{Algorithm: u.Murmur3, Crosses: [][]string{{"city", "size"}}}
+  encoder := NewHashEncoder(opt)
+  encoder.Fit(df)
+  result, err := encoder.TransformView(df)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  u.AssertIntEquals("input", result.Ints("size").Get(0), 3, t)
+  crosses := result.Ints("city_x_size")
+  u.AssertIntEquals("same pair", crosses.Get(0), crosses.Get(2), t)
+  u.AssertIntNotEquals("other city", crosses.Get(0), crosses.Get(1), t)
+  u.AssertIntNotEquals("other size", crosses.Get(1), crosses.Get(4), t)
+  u.AssertIntEquals("missing", crosses.Get(3), -1, t)
+}
+
+func TestHashEncoderInvalidCross(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddStrings("city", "paris", "tokyo")
+  builder.AddInts("size", 3, 4)
+  df := builder.AddFloats("height", 1, 2).ToDataFrame()
+  opt := HashEncoderOptions{Crosses: [][]string{{"city", "size"}}}
+  encoder := NewHashEncoder(opt)
+  encoder.Fit(df)
+  u.AssertStringSliceEquals("inputs", encoder.TransformedColumns(), []string{"city", "size"}, true, t)
+
+  for _, cross := range [][]string{{"city", "nope"}, {"city", "height"}} {
+    encoder.Options.Crosses = [][]string{cross}
+    if _, err := encoder.TransformView(df); err == nil {
+      t.Errorf("invalid cross input %s should be reported", cross[1])
+    }
+  }
+}
@@ -2,6 +2,7 @@ package preprocessing
 
 import (
   "fmt"
+  "sort"
   "github.com/rom1mouret/ml-essentials/utils"
   "github.com/rom1mouret/ml-essentials/dataframe"
 )
@@ -10,11 +11,24 @@ type OneHotOptions struct {
   MissingPolicy   CategoryPolicy
   UnknownPolicy   CategoryPolicy
   KeepUsedColumns bool
+  // Maximum number of columns per categorical column, not counting the
+  // missing, unknown and other columns. Zero means no limit.
+  MaxCategories   int
+  // Categories that are less frequent than MinFrequency in the training data
+  // are not given their own column. If MinFrequency is lower than 1, it is a
+  // ratio of the number of training rows, otherwise it is a count.
+  // The most frequent category always gets its own column, ties being broken
+  // in favor of the smallest category.
+  MinFrequency    float64
 }
 
 // OneHotencoder is a json-serializable structure that transforms integer-typed
 // categorical values into boolean columns.
 // https://en.wikipedia.org/wiki/One-hot
+// Categories maps each categorical column to its categories and their column.
+// If MaxCategories or MinFrequency is set, the infrequent categories share the
+// same column, named <col>_other. Categories that were not seen during
+// training are still handled according to UnknownPolicy.
 type OneHotEncoder struct {
   CategoricalColumns []string
   NewColumns         []string
@@ -66,23 +80,32 @@ func (encoder *OneHotEncoder) workerFits(df *dataframe.DataFrame, q utils.String
     _, missingSeen := freqs[-1]
     delete(freqs, -1)
 
-    // find which category is the most frequent
+    // define the new columns, from the most frequent category to the least
+    // frequent one
+    byFrequency := make([]int, 0, len(freqs))
+    for category := range freqs {
+      byFrequency = append(byFrequency, category)
+    }
+    sort.Slice(byFrequency, func(i, j int) bool {
+      a, b := byFrequency[i], byFrequency[j]
+      return freqs[a] > freqs[b] || (freqs[a] == freqs[b] && a < b)
+    })
+    // the most frequent category always gets its own column
     var mostCommon int
-    if opt.MissingPolicy != ReturnError || opt.UnknownPolicy != ReturnError {
-      maxFreq := -1
-      for category, frequency := range freqs {
-        if frequency > maxFreq {
-          maxFreq = frequency
-          mostCommon = category
-        }
-      }
+    if len(byFrequency) > 0 {
+      mostCommon = byFrequency[0]
+    }
+    minFreq := opt.MinFrequency
+    if minFreq < 1 {
+      minFreq *= float64(access.Size())
     }
-    // define the new columns
     categoryToNewCol := encoder.Categories[col]
-    i := 0
-    for category := range(freqs) {
-      categoryToNewCol[category] = fmt.Sprintf("%s_onehot%d", col, i)
-      i++
+    for i, category := range byFrequency {
+      if i > 0 && ((opt.MaxCategories > 0 && i >= opt.MaxCategories) || float64(freqs[category]) < minFreq) {
+        categoryToNewCol[category] = fmt.Sprintf("%s_other", col)
+      } else {
+        categoryToNewCol[category] = fmt.Sprintf("%s_onehot%d", col, i)
+      }
     }
     fallback := encoder.Fallback[col]
     if opt.MissingPolicy == SeparateCategoryIfSeen {
@@ -246,7 +269,119 @@ func (opt* OneHotOptions) fix() {
   }
 }
 
-// func (encoder *OneHotEncoder) InverseTransformInplace() {
-//   // TODO: lazily construct the inverse table
-//   // protect the construction with a Mutex
-// }
+// inverseTable maps the new columns of the given categorical column to their
+// category. Missing, unknown and other columns are mapped to -1.
+func (encoder *OneHotEncoder) inverseTable(catCol string) map[string]int {
+  result := make(map[string]int)
+  for _, fallback := range encoder.Fallback[catCol] {
+    if len(fallback) > 0 {
+      result[fallback] = -1
+    }
+  }
+  // categories override the fallbacks that impute the most frequent category
+  for category, newCol := range encoder.Categories[catCol] {
+    result[newCol] = category
+  }
+  // the column of the infrequent categories cannot be inverted, even if only
+  // one category was capped
+  otherCol := fmt.Sprintf("%s_other", catCol)
+  if _, exists := result[otherCol]; exists {
+    result[otherCol] = -1
+  }
+  return result
+}
+
+func (encoder *OneHotEncoder) workerInverses(df *dataframe.DataFrame, q utils.StringQ) {
+  floatCols := df.FloatHeader().NameSet()
+  boolCols := df.BoolHeader().NameSet()
+  for catCol := q.Next(); len(catCol) > 0; catCol = q.Next() {
+    notif := utils.ProcessedJob{Key: catCol}
+    table := encoder.inverseTable(catCol)
+    newCols := make([]string, 0, len(table))
+    for newCol := range table {
+      newCols = append(newCols, newCol)
+    }
+    sort.Strings(newCols)  // deterministic tie breaking
+    // value getters of the one-hot columns
+    getters := make([]func(i int) float64, 0, len(newCols))
+    var access dataframe.ColumnAccess
+    for _, newCol := range newCols {
+      if floatCols[newCol] {
+        floats := df.Floats(newCol)
+        access = floats.ColumnAccess
+        getters = append(getters, floats.Get)
+      } else if boolCols[newCol] {
+        bools := df.Bools(newCol)
+        access = bools.ColumnAccess
+        getters = append(getters, func(i int) float64 {
+          if bools.Get(i) {
+            return 1
+          }
+          return 0
+        })
+      } else {
+        notif.Error = fmt.Errorf("column %s is missing", newCol)
+        break
+      }
+    }
+    if notif.Error == nil && len(getters) == 0 {
+      notif.Error = fmt.Errorf("no one-hot column for %s", catCol)
+    }
+    if notif.Error == nil {
+      result := make([]int, df.NumAllocatedRows())
+      for i := 0; i < df.NumRows(); i++ {
+        // argmax, ignoring zeros and NaNs
+        best := -1
+        bestVal := 0.0
+        for k, get := range getters {
+          if val := get(i); val > bestVal {
+            bestVal = val
+            best = table[newCols[k]]
+          }
+        }
+        result[access.SharedIndex(i)] = best
+      }
+      notif.Result = result
+    }
+    q.Notify(notif)
+  }
+}
+
+// InverseTransformInplace collapses the bool columns created by
+// TransformView into the original int columns. The one-hot columns may also
+// be float columns, e.g. probabilities predicted by a multi-class model, in
+// which case the category with the highest value is chosen.
+// Rows where the missing, unknown or other column wins, as well as rows where
+// all the values are zero or NaN, are converted to -1.
+// The one-hot columns are removed from df.
+// It returns an error if df is frozen or a one-hot column is missing, in which
+// case df is not altered.
+// This function is multi-threaded.
+func (encoder *OneHotEncoder) InverseTransformInplace(df *dataframe.DataFrame) error {
+  if len(encoder.CategoricalColumns) == 0 {
+    return nil
+  }
+  if err := df.CheckWritable(); err != nil {
+    return err
+  }
+  q := df.CreateColumnQueue(encoder.CategoricalColumns)
+  for i := 0; i < q.Workers; i++ {
+    go encoder.workerInverses(df, q)
+  }
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  var err error
+  for _, job := range q.Results() {
+    if job.Error != nil {
+      err = job.Error
+    } else {
+      builder.SetInts(job.Key, job.Result.([]int))
+    }
+  }
+  if err != nil {
+    return err
+  }
+  df.Drop(encoder.NewColumns...)
+  df.Drop(encoder.CategoricalColumns...)  // if KeepUsedColumns
+  df.TransferRawDataFrom(builder.RawData)
+  return nil
+}
@@ -2,6 +2,7 @@ package preprocessing
 
 import (
   "testing"
+  "math"
   "encoding/json"
   "github.com/rom1mouret/ml-essentials/dataframe"
   u "github.com/rom1mouret/ml-essentials/utils"
@@ -15,6 +16,14 @@ func rowSum(row []float32) float64 {
   return sum
 }
 
+func boolValues(access dataframe.BoolAccess) []bool {
+  result := make([]bool, access.Size())
+  for i := range result {
+    result[i] = access.Get(i)
+  }
+  return result
+}
+
 func Test1HotBasics(t *testing.T) {
   builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
   df := builder.AddInts("col", 1, 2, 3, 2, 2, 2).ToDataFrame()
@@ -156,3 +165,133 @@ func TestErrorCatching(t *testing.T) {
     secondVal = 10
   }
 }
+
+func Test1HotInverse(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  df := builder.AddInts("col", 1, 2, 3, 2, -1).ToDataFrame()
+  encoder := NewOneHotEncoder(OneHotOptions{MissingPolicy: SeparateCategory, UnknownPolicy: SeparateCategory})
+  encoder.Fit(df)
+
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  df = builder.AddInts("col", 3, -1, 2, 7, 1).ToDataFrame()
+  transformed, err := encoder.TransformView(df)
+  if err != nil {
+    t.Errorf(err.Error())
+    return
+  }
+  if err := encoder.InverseTransformInplace(transformed); err != nil {
+    t.Errorf(err.Error())
+    return
+  }
+  u.AssertIntEquals("num columns", transformed.NumColumns(), 1, t)
+  codes := transformed.Ints("col")
+  u.AssertIntSliceEquals("categories", []int{codes.Get(0), codes.Get(1), codes.Get(2), codes.Get(3), codes.Get(4)}, []int{3, -1, 2, -1, 1}, t)
+}
+
+func Test1HotInverseProbabilities(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  df := builder.AddInts("col", 4, 5, 4).ToDataFrame()
+  encoder := NewOneHotEncoder(OneHotOptions{MissingPolicy: ImputeWithMostFrequent, UnknownPolicy: ReturnError})
+  encoder.Fit(df)
+
+  // probabilities predicted by a model
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddFloats(encoder.Categories["col"][4], 0.7, 0.2, 0, math.NaN())
+  builder.AddFloats(encoder.Categories["col"][5], 0.3, 0.8, 0, math.NaN())
+  predictions := builder.ToDataFrame()
+  if err := encoder.InverseTransformInplace(predictions); err != nil {
+    t.Errorf(err.Error())
+    return
+  }
+  codes := predictions.Ints("col")
+  u.AssertIntSliceEquals("categories", []int{codes.Get(0), codes.Get(1), codes.Get(2), codes.Get(3)}, []int{4, 5, -1, -1}, t)
+
+  // missing one-hot column
+  predictions.Drop("col")
+  if encoder.InverseTransformInplace(predictions) == nil {
+    t.Errorf("missing columns should be reported")
+  }
+}
+
+func Test1HotFrequencyCapping(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  df := builder.AddInts("col", 1, 1, 1, 1, 2, 2, 2, 3, 3, 4).ToDataFrame()
+  opt := OneHotOptions{MissingPolicy: ReturnError, UnknownPolicy: SeparateCategory, MaxCategories: 2}
+  encoder := NewOneHotEncoder(opt)
+  encoder.Fit(df)
+  u.AssertIntEquals("num new cols", len(encoder.NewColumns), 4, t)
+  u.AssertStringEquals("other", encoder.Categories["col"][3], "col_other", t)
+  u.AssertStringEquals("other", encoder.Categories["col"][4], "col_other", t)
+
+  // ratio
+  opt = OneHotOptions{MissingPolicy: ReturnError, UnknownPolicy: SeparateCategory, MinFrequency: 0.25}
+  encoder = NewOneHotEncoder(opt)
+  encoder.Fit(df)
+  u.AssertIntEquals("num new cols", len(encoder.NewColumns), 4, t)
+  u.AssertStringNotEquals("frequent", encoder.Categories["col"][2], "col_other", t)
+  u.AssertStringEquals("infrequent", encoder.Categories["col"][3], "col_other", t)
+
+  // count, with serialization
+  opt.MinFrequency = 3
+  encoder = NewOneHotEncoder(opt)
+  encoder.Fit(df)
+  serialized, _ := json.Marshal(encoder)
+  encoder = &OneHotEncoder{}
+  json.Unmarshal([]byte(serialized), &encoder)
+
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  df = builder.AddInts("col", 1, 2, 3, 4, 5).ToDataFrame()
+  result, err := encoder.TransformView(df)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  u.AssertBoolSliceEquals("other", boolValues(result.Bools("col_other")), []bool{false, false, true, true, false}, t)
+  u.AssertBoolSliceEquals("unknown", boolValues(result.Bools("col_unk")), []bool{false, false, false, false, true}, t)
+
+  // the other column is inverted to -1
+  if !u.AssertNoError(encoder.InverseTransformInplace(result), t) {
+    return
+  }
+  codes := result.Ints("col")
+  u.AssertIntSliceEquals("inverse", []int{codes.Get(0), codes.Get(1), codes.Get(2), codes.Get(3), codes.Get(4)}, []int{1, 2, -1, -1, -1}, t)
+}
+
+func Test1HotCappingTies(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  df := builder.AddInts("c", 4, 3, 2, 1).ToDataFrame()
+  opt := OneHotOptions{MissingPolicy: ImputeWithMostFrequent, UnknownPolicy: ImputeWithMostFrequent, MaxCategories: 1}
+  // the result used to depend on the map iteration order
+  for run := 0; run < 20; run++ {
+    encoder := NewOneHotEncoder(opt)
+    encoder.Fit(df)
+    kept := encoder.Categories["c"][1]
+    u.AssertStringNotEquals("kept", kept, "c_other", t)
+    u.AssertStringEquals("missing", encoder.Fallback["c"][0], kept, t)
+    u.AssertStringEquals("unknown", encoder.Fallback["c"][1], kept, t)
+  }
+  // the most frequent category is kept whatever MinFrequency
+  opt = OneHotOptions{MissingPolicy: ImputeWithMostFrequent, UnknownPolicy: ReturnError, MinFrequency: 0.9}
+  encoder := NewOneHotEncoder(opt)
+  encoder.Fit(df)
+  u.AssertStringNotEquals("min freq", encoder.Fallback["c"][0], "c_other", t)
+}
+
+func Test1HotInverseSingleOther(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  df := builder.AddInts("c", 1, 1, 1, 2, 2, 3).ToDataFrame()
+  opt := OneHotOptions{MissingPolicy: ReturnError, UnknownPolicy: SeparateCategory, MaxCategories: 2}
+  encoder := NewOneHotEncoder(opt)
+  encoder.Fit(df)
+  u.AssertStringEquals("capped", encoder.Categories["c"][3], "c_other", t)
+
+  // only category 3 is routed to the other column, yet it cannot be inverted
+  result, err := encoder.TransformView(df)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  if !u.AssertNoError(encoder.InverseTransformInplace(result), t) {
+    return
+  }
+  u.AssertIntEquals("frequent", result.Ints("c").Get(3), 2, t)
+  u.AssertIntEquals("other", result.Ints("c").Get(5), -1, t)
+}
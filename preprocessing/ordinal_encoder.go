@@ -0,0 +1,330 @@
+package preprocessing
+
+import (
+  "fmt"
+  "sort"
+  "github.com/rom1mouret/ml-essentials/utils"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+)
+
+type CategoryOrder int
+
+const (
+  // The most frequent category gets code 0, the second most frequent gets
+  // code 1 etc. Ties are broken alphabetically.
+  ByFrequency CategoryOrder = iota
+  // Categories are sorted alphabetically.
+  Lexical
+  // Categories are ordered as in OrdinalOptions.Categories.
+  UserProvided
+)
+
+type OrdinalOptions struct {
+  Order         CategoryOrder
+  // Ordered categories of each column, if Order is UserProvided.
+  Categories    map[string][]string
+  MissingPolicy CategoryPolicy
+  UnknownPolicy CategoryPolicy
+}
+
+// OrdinalEncoder is a json-serializable structure that transforms string
+// columns into int columns of category codes, from 0 to the number of
+// categories. Unlike HashEncoder, codes are small and the transformation can
+// be inverted.
+// Categories maps each column to its known categories, indexed by code.
+// Depending on the policies, missing and unknown categories are encoded with
+// the codes that follow the known categories, with the code of the most
+// frequent category, or they cause an error.
+type OrdinalEncoder struct {
+  CategoricalColumns []string
+  Categories         map[string][]string
+  MissingCodes       map[string]int
+  UnknownCodes       map[string]int
+  Options            OrdinalOptions
+}
+
+// NewOrdinalEncoder allocates a new OrdinalEncoder.
+func NewOrdinalEncoder(options OrdinalOptions) *OrdinalEncoder {
+  encoder := new(OrdinalEncoder)
+  encoder.Options = options
+  encoder.Options.fix()
+
+  return encoder
+}
+
+type ordinalFitting struct {
+  categories  []string
+  missingCode int
+  unknownCode int
+  err         error
+}
+
+func (encoder *OrdinalEncoder) fitColumn(access dataframe.ObjectAccess, col string) ordinalFitting {
+  opt := encoder.Options
+  // compute the frequency of each category
+  freqs := make(map[string]int)
+  missingSeen := false
+  for i := 0; i < access.Size(); i++ {
+    if s, ok := access.Get(i).(string); ok {
+      freqs[s]++
+    } else {
+      missingSeen = true
+    }
+  }
+  byFrequency := make([]string, 0, len(freqs))
+  for category := range freqs {
+    byFrequency = append(byFrequency, category)
+  }
+  sort.Slice(byFrequency, func(i, j int) bool {
+    a, b := byFrequency[i], byFrequency[j]
+    return freqs[a] > freqs[b] || (freqs[a] == freqs[b] && a < b)
+  })
+  // order the categories
+  result := ordinalFitting{missingCode: -1, unknownCode: -1}
+  switch opt.Order {
+  case UserProvided:
+    categories, ok := opt.Categories[col]
+    if !ok {
+      result.err = fmt.Errorf("no categories provided for column %s", col)
+      return result
+    }
+    result.categories = categories
+  case Lexical:
+    result.categories = make([]string, len(byFrequency))
+    copy(result.categories, byFrequency)
+    sort.Strings(result.categories)
+  default:
+    result.categories = byFrequency
+  }
+  // most frequent category that is also a known category
+  mostCommon := -1
+  codes := make(map[string]int)
+  for code, category := range result.categories {
+    codes[category] = code
+  }
+  for _, category := range byFrequency {
+    if code, ok := codes[category]; ok {
+      mostCommon = code
+      break
+    }
+  }
+  if mostCommon < 0 && len(result.categories) > 0 {
+    mostCommon = 0
+  }
+  // codes of missing and unknown categories
+  nextCode := len(result.categories)
+  switch opt.MissingPolicy {
+  case SeparateCategoryIfSeen:
+    if missingSeen {
+      result.missingCode = nextCode
+      nextCode++
+    } else {
+      result.missingCode = mostCommon
+    }
+  case SeparateCategory:
+    result.missingCode = nextCode
+    nextCode++
+  case ImputeWithMostFrequent:
+    result.missingCode = mostCommon
+  }
+  switch opt.UnknownPolicy {
+  case SeparateCategory:
+    result.unknownCode = nextCode
+  case ImputeWithMostFrequent:
+    result.unknownCode = mostCommon
+  case SharedSeparateCategory:
+    if result.missingCode >= len(result.categories) {
+      result.unknownCode = result.missingCode
+    } else {
+      result.unknownCode = nextCode  // no separate category to share
+    }
+  }
+  return result
+}
+
+func (encoder *OrdinalEncoder) workerFits(df *dataframe.DataFrame, q utils.StringQ) {
+  for col := q.Next(); len(col) > 0; col = q.Next() {
+    result := encoder.fitColumn(df.Objects(col), col)
+    q.Notify(utils.ProcessedJob{Key: col, Result: &result, Error: result.err})
+  }
+}
+
+// Fit implements PreprocTraining and Transform interfaces.
+// It learns the categories of every string column.
+// It returns an error if Order is UserProvided and the categories of a string
+// column are not provided.
+func (encoder *OrdinalEncoder) Fit(df *dataframe.DataFrame) error {
+  columns := df.StringHeader().NameList()
+  sort.Strings(columns)
+  encoder.CategoricalColumns = columns
+  encoder.Categories = make(map[string][]string)
+  encoder.MissingCodes = make(map[string]int)
+  encoder.UnknownCodes = make(map[string]int)
+
+  q := df.CreateColumnQueue(columns)
+  for i := 0; i < q.Workers; i++ {
+    go encoder.workerFits(df, q)
+  }
+  var err error
+  for _, job := range q.Results() {
+    if job.Error != nil {
+      err = job.Error
+      continue
+    }
+    result := job.Result.(*ordinalFitting)
+    encoder.Categories[job.Key] = result.categories
+    encoder.MissingCodes[job.Key] = result.missingCode
+    encoder.UnknownCodes[job.Key] = result.unknownCode
+  }
+  return err
+}
+
+func (encoder *OrdinalEncoder) workerTransforms(df *dataframe.DataFrame, q utils.StringQ) {
+  for col := q.Next(); len(col) > 0; col = q.Next() {
+    notif := utils.ProcessedJob{Key: col}
+    codes := make(map[string]int)
+    for code, category := range encoder.Categories[col] {
+      codes[category] = code
+    }
+    missingCode := encoder.MissingCodes[col]
+    unknownCode := encoder.UnknownCodes[col]
+    access := df.Objects(col)
+    result := make([]int, df.NumAllocatedRows())
+    for i := 0; i < access.Size(); i++ {
+      j := access.SharedIndex(i)
+      if s, ok := access.Get(i).(string); !ok {
+        if missingCode < 0 {
+          notif.Error = fmt.Errorf("missing value in column %s", col)
+          break
+        }
+        result[j] = missingCode
+      } else if code, ok := codes[s]; ok {
+        result[j] = code
+      } else if unknownCode < 0 {
+        notif.Error = fmt.Errorf("%s: unknown category in column %s", s, col)
+        break
+      } else {
+        result[j] = unknownCode
+      }
+    }
+    notif.Result = result
+    q.Notify(notif)
+  }
+}
+
+// TransformInplace implements PreprocTraining and InplaceTransform interfaces.
+// The string columns are replaced with int columns of the same name.
+// It returns an error if df is frozen, if a string column is missing, or if a missing or
+// unknown category is found and the corresponding policy is ReturnError, in
+// which case df is not altered.
+// This function is multi-threaded.
+func (encoder *OrdinalEncoder) TransformInplace(df *dataframe.DataFrame) error {
+  if len(encoder.CategoricalColumns) == 0 {
+    return nil
+  }
+  if err := df.CheckWritable(); err != nil {
+    return err
+  }
+  stringCols := df.StringHeader().NameSet()
+  for _, col := range encoder.CategoricalColumns {
+    if !stringCols[col] {
+      return fmt.Errorf("%s is not a string column", col)
+    }
+  }
+  q := df.CreateColumnQueue(encoder.CategoricalColumns)
+  for i := 0; i < q.Workers; i++ {
+    go encoder.workerTransforms(df, q)
+  }
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  var err error
+  for _, job := range q.Results() {
+    if job.Error != nil {
+      err = job.Error
+    } else {
+      builder.SetInts(job.Key, job.Result.([]int))
+    }
+  }
+  if err != nil {
+    return err
+  }
+  df.Drop(encoder.CategoricalColumns...)
+  df.TransferRawDataFrom(builder.RawData)
+  return nil
+}
+
+// TransformView implements PreprocTraining and Transform interfaces.
+// It is functionally equivalent to:
+//  view := df.View()
+//  err := encoder.TransformInplace(view)
+func (encoder *OrdinalEncoder) TransformView(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+  result := df.View()
+  if err := encoder.TransformInplace(result); err != nil {
+    return nil, err
+  }
+  return result, nil
+}
+
+// InverseTransformInplace implements InverseInplaceTransform interface.
+// The int columns are replaced with string columns of the same name.
+// Missing and unknown codes, as well as -1, are converted to missing strings,
+// unless they were imputed with the most frequent category.
+// It returns an error if df is frozen, if an int column is missing or if a
+// code is out of range, in which case df is not altered.
+func (encoder *OrdinalEncoder) InverseTransformInplace(df *dataframe.DataFrame) error {
+  if err := df.CheckWritable(); err != nil {
+    return err
+  }
+  intCols := df.IntHeader().NameSet()
+  for _, col := range encoder.CategoricalColumns {
+    if !intCols[col] {
+      return fmt.Errorf("%s is not an int column", col)
+    }
+  }
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  nRows := df.NumAllocatedRows()
+  for _, col := range encoder.CategoricalColumns {
+    categories := encoder.Categories[col]
+    maxCode := len(categories) - 1
+    if code := encoder.MissingCodes[col]; code > maxCode {
+      maxCode = code
+    }
+    if code := encoder.UnknownCodes[col]; code > maxCode {
+      maxCode = code
+    }
+    access := df.Ints(col)
+    result := make([]interface{}, nRows)
+    for i := 0; i < access.Size(); i++ {
+      code := access.Get(i)
+      if code < -1 || code > maxCode {
+        return fmt.Errorf("code %d out of range in column %s", code, col)
+      }
+      if code >= 0 && code < len(categories) {
+        result[access.SharedIndex(i)] = categories[code]
+      }
+    }
+    builder.SetObjects(col, result).MarkAsString(col)
+  }
+  df.Drop(encoder.CategoricalColumns...)
+  df.TransferRawDataFrom(builder.RawData)
+  return nil
+}
+
+// TransformedColumns implements PreprocTraining and Transform interfaces.
+func (encoder *OrdinalEncoder) TransformedColumns() []string {
+  return encoder.CategoricalColumns
+}
+
+func (opt *OrdinalOptions) fix() {
+  // same rules as OneHotOptions
+  if opt.MissingPolicy == SharedSeparateCategory {
+    if opt.UnknownPolicy != SharedSeparateCategory {
+      opt.MissingPolicy = opt.UnknownPolicy
+      opt.UnknownPolicy = SharedSeparateCategory
+    } else {
+      opt.MissingPolicy = SeparateCategoryIfSeen
+    }
+  }
+  if opt.UnknownPolicy == SeparateCategoryIfSeen {
+    opt.UnknownPolicy = SeparateCategory
+  }
+}
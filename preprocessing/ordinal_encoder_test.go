@@ -0,0 +1,98 @@
+package preprocessing
+
+import (
+  "testing"
+  "encoding/json"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+  u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func TestOrdinalEncoder(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddStrings("city", "paris", "tokyo", "tokyo", "lima", "")
+  builder.AddFloats("height", 1, 2, 3, 4, 5)
+  df := builder.ToDataFrame()
+  df.Objects("city").Set(4, nil)
+
+  encoder := NewOrdinalEncoder(OrdinalOptions{UnknownPolicy: SeparateCategory})
+  u.AssertNoError(encoder.Fit(df), t)
+  u.AssertStringSliceEquals("categories", encoder.Categories["city"], []string{"tokyo", "lima", "paris"}, true, t)
+  u.AssertIntEquals("missing code", encoder.MissingCodes["city"], 3, t)
+  u.AssertIntEquals("unknown code", encoder.UnknownCodes["city"], 4, t)
+
+  // serialization
+  serialized, _ := json.Marshal(encoder)
+  encoder = &OrdinalEncoder{}
+  json.Unmarshal(serialized, &encoder)
+
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddStrings("city", "lima", "rome", "tokyo", "")
+  test := builder.ToDataFrame()
+  test.Objects("city").Set(3, nil)
+  result, err := encoder.TransformView(test.ReverseView())
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  u.AssertIntEquals("num strings", result.StringHeader().Num(), 0, t)
+  u.AssertIntEquals("input", test.StringHeader().Num(), 1, t)
+  codes := result.Ints("city")
+  u.AssertIntSliceEquals("codes", []int{codes.Get(0), codes.Get(1), codes.Get(2), codes.Get(3)}, []int{3, 0, 4, 1}, t)
+
+  // inverse
+  u.AssertNoError(encoder.InverseTransformInplace(result), t)
+  cities := result.Objects("city")
+  u.AssertTrue("missing", cities.Get(0) == nil, t)
+  u.AssertTrue("unknown", cities.Get(2) == nil, t)
+  u.AssertStringEquals("known", result.Strings("city").Get(3), "lima", t)
+}
+
+func TestOrdinalEncoderPolicies(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  df := builder.AddStrings("size", "M", "S", "L", "S").ToDataFrame()
+
+  opt := OrdinalOptions{
+    Order: UserProvided,
+    Categories: map[string][]string{"size": []string{"S", "M", "L", "XL"}},
+    MissingPolicy: ReturnError,
+    UnknownPolicy: ImputeWithMostFrequent,
+  }
+  encoder := NewOrdinalEncoder(opt)
+  u.AssertNoError(encoder.Fit(df), t)
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  test := builder.AddStrings("size", "XL", "XXL", "").ToDataFrame()
+  result, err := encoder.TransformView(test.IndexView([]int{0, 1}))
+  u.AssertNoError(err, t)
+  u.AssertIntEquals("known", result.Ints("size").Get(0), 3, t)
+  u.AssertIntEquals("imputed", result.Ints("size").Get(1), 0, t)
+
+  test.Objects("size").Set(2, nil)
+  _, err = encoder.TransformView(test)
+  u.AssertTrue("missing", err != nil, t)
+  u.AssertIntEquals("unaltered", test.StringHeader().Num(), 1, t)
+
+  opt.Order = Lexical
+  encoder = NewOrdinalEncoder(opt)
+  encoder.Fit(df)
+  u.AssertStringSliceEquals("lexical", encoder.Categories["size"], []string{"L", "M", "S"}, true, t)
+
+  opt.Categories = nil
+  opt.Order = UserProvided
+  u.AssertTrue("no categories", NewOrdinalEncoder(opt).Fit(df) != nil, t)
+}
+
+func TestOrdinalEncoderWrongColumns(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  df := builder.AddStrings("size", "M", "S").ToDataFrame()
+  encoder := NewOrdinalEncoder(OrdinalOptions{})
+  u.AssertNoError(encoder.Fit(df), t)
+
+  // "size" is still a string column
+  u.AssertTrue("not int", encoder.InverseTransformInplace(df) != nil, t)
+  u.AssertStringEquals("unaltered", df.Strings("size").Get(0), "M", t)
+
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  other := builder.AddInts("size", 0, 1).ToDataFrame()
+  u.AssertTrue("not string", encoder.TransformInplace(other) != nil, t)
+  other.Drop("size")
+  u.AssertTrue("missing", encoder.InverseTransformInplace(other) != nil, t)
+}
@@ -130,6 +130,7 @@ func (scaler *Scaler) workerTransforms(df *dataframe.DataFrame, q utils.StringQ)
 }
 
 // TransformInplace implements InplaceTransform interface.
+// It returns an error if df is frozen, in which case df is not altered.
 func (scaler *Scaler) TransformInplace(df *dataframe.DataFrame) error {
   // divide into column groups
   var m map[string]float64
@@ -141,6 +142,9 @@ func (scaler *Scaler) TransformInplace(df *dataframe.DataFrame) error {
   if len(m) == 0 {
     return nil
   }
+  if err := df.CheckWritable(scaler.TransformedColumns()...); err != nil {
+    return err
+  }
   q := df.CreateColumnQueue(scaler.TransformedColumns())
   defer q.Wait()
   for i := 0; i < q.Workers; i++ {
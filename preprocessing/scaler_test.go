@@ -100,3 +100,28 @@ func TestCenteringScaling(t *testing.T) {
   u.AssertFloatEquals("vals[2]", vals.Get(2), 2.0, t)
   u.AssertFloatEquals("vals[3]", vals.Get(3), 2.0, t)
 }
+
+func TestInplaceFrozen(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddFloats("col", 1.0, math.NaN(), 2.0)
+  df := builder.AddStrings("city", "paris", "lima", "paris").ToDataFrame()
+  scaler := NewScaler(ScalerOptions{Centering: true})
+  scaler.Fit(df)
+  imputer := NewFloatImputer(FloatImputerOptions{})
+  imputer.Fit(df)
+  encoder := NewOrdinalEncoder(OrdinalOptions{})
+  encoder.Fit(df)
+
+  frozen := df.Freeze()
+  u.AssertTrue("scaler", scaler.TransformInplace(frozen) != nil, t)
+  u.AssertTrue("imputer", imputer.TransformInplace(frozen.View()) != nil, t)
+  u.AssertTrue("ordinal", encoder.TransformInplace(frozen) != nil, t)
+  u.AssertTrue("unaltered", math.IsNaN(frozen.Floats("col").Get(1)), t)
+
+  // the frozen columns can be replaced in a view
+  result, err := encoder.TransformView(frozen)
+  u.AssertNoError(err, t)
+  u.AssertIntEquals("encoded", result.Ints("city").Get(0), result.Ints("city").Get(2), t)
+  detached := frozen.DetachedView("col")
+  u.AssertNoError(scaler.TransformInplace(detached), t)
+}
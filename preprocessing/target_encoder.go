@@ -0,0 +1,487 @@
+package preprocessing
+
+import (
+  "fmt"
+  "math"
+  "math/rand"
+  "sort"
+  "strconv"
+  "github.com/rom1mouret/ml-essentials/utils"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+)
+
+type TargetEncoderOptions struct {
+  // Name of the target column. Default: "target"
+  TargetColumn    string
+  // Weight of the global mean in the encoding of each category, expressed as
+  // a number of pseudo-observations. Zero means no smoothing.
+  Smoothing       float64
+  // Number of folds used by FitTransform. Default: 5
+  // If NumFolds is 1, FitTransform is equivalent to Fit+TransformView.
+  NumFolds        int
+  // Seed of the random assignment of the rows to the folds.
+  Seed            int64
+  // If true, the target is an int or string column of classes and each
+  // categorical column is encoded with the probability of each class.
+  // Otherwise, the target is a float or int column and each categorical
+  // column is encoded with the mean of the target.
+  MultiClass      bool
+  // Unless KeepUsedColumns is true, the categorical columns are removed from
+  // the transformed dataframe.
+  KeepUsedColumns bool
+}
+
+// TargetEncoder is a json-serializable structure that replaces categories
+// with the mean of the target, smoothed towards the global mean:
+//  (sum of category's targets + Smoothing * global mean) / (count + Smoothing)
+// It transforms int and string columns into float columns named <col>_te, or
+// <col>_te<k> if MultiClass is true, where k is the index of the class in
+// Classes.
+// Encodings maps each categorical column to the encodings of its categories.
+// Int categories are converted to strings so as to be json-serializable.
+// Missing and unknown categories are encoded with the global mean, Prior.
+// Rows with a missing target are ignored during training.
+type TargetEncoder struct {
+  CategoricalColumns []string
+  Classes            []string
+  Prior              []float64
+  Encodings          map[string]map[string][]float64
+  Options            TargetEncoderOptions
+}
+
+// NewTargetEncoder allocates a new TargetEncoder.
+func NewTargetEncoder(opt TargetEncoderOptions) *TargetEncoder {
+  encoder := new(TargetEncoder)
+  if len(opt.TargetColumn) == 0 {
+    opt.TargetColumn = "target"
+  }
+  if opt.NumFolds <= 0 {
+    opt.NumFolds = 5
+  }
+  encoder.Options = opt
+  return encoder
+}
+
+// targetStats accumulates the targets of a category, or of all categories.
+type targetStats struct {
+  count float64
+  sums  []float64
+}
+
+func (stats *targetStats) add(target int, value float64) {
+  stats.count++
+  stats.sums[target] += value
+}
+
+// minus returns the statistics of stats without the other statistics.
+func (stats *targetStats) minus(other *targetStats) *targetStats {
+  if other == nil {
+    return stats
+  }
+  result := &targetStats{count: stats.count - other.count, sums: make([]float64, len(stats.sums))}
+  for k := range stats.sums {
+    result.sums[k] = stats.sums[k] - other.sums[k]
+  }
+  return result
+}
+
+// mean returns the averaged targets, or nil if no target has been added.
+func (stats *targetStats) mean() []float64 {
+  if stats.count <= 0 {
+    return nil
+  }
+  result := make([]float64, len(stats.sums))
+  for k := range result {
+    result[k] = stats.sums[k] / stats.count
+  }
+  return result
+}
+
+// smoothed returns the averaged targets, smoothed towards the prior.
+func (stats *targetStats) smoothed(prior []float64, smoothing float64) []float64 {
+  if stats == nil || stats.count + smoothing <= 0 {
+    return prior
+  }
+  result := make([]float64, len(stats.sums))
+  for k := range result {
+    result[k] = (stats.sums[k] + smoothing * prior[k]) / (stats.count + smoothing)
+  }
+  return result
+}
+
+// targets holds the target of each row, either as a class index or as a
+// value. Missing targets are -1 or NaN.
+type targets struct {
+  classes []int
+  values  []float64
+}
+
+// addTo adds the target of the given row to the statistics.
+func (t *targets) addTo(stats *targetStats, row int) {
+  if t.classes != nil {
+    if class := t.classes[row]; class >= 0 {
+      stats.add(class, 1)
+    }
+  } else if value := t.values[row]; !math.IsNaN(value) {
+    stats.add(0, value)
+  }
+}
+
+// categoryKeys returns a function that converts the categories of an int or
+// string column into strings. The second returned value is false if the
+// category is missing.
+func categoryKeys(df *dataframe.DataFrame, col string) func(i int) (string, bool) {
+  if df.StringHeader().NameSet()[col] {
+    access := df.Objects(col)
+    return func(i int) (string, bool) {
+      s, ok := access.Get(i).(string)
+      return s, ok
+    }
+  }
+  access := df.Ints(col)
+  return func(i int) (string, bool) {
+    if v := access.Get(i); v != -1 {
+      return strconv.Itoa(v), true
+    }
+    return "", false
+  }
+}
+
+// checkCategoricalColumns returns an error if one of the given columns is
+// neither an int column nor a string column.
+func checkCategoricalColumns(df *dataframe.DataFrame, columns []string) error {
+  categorical := df.IntHeader().And(df.StringHeader()).NameSet()
+  for _, col := range columns {
+    if !categorical[col] {
+      return fmt.Errorf("%s is neither an int column nor a string column", col)
+    }
+  }
+  return nil
+}
+
+// readTargets reads the target column. It also sets encoder.Classes if the
+// target is multi-class and the classes are not known yet.
+func (encoder *TargetEncoder) readTargets(df *dataframe.DataFrame) (*targets, error) {
+  col := encoder.Options.TargetColumn
+  isString := df.StringHeader().NameSet()[col]
+  isInt := df.IntHeader().NameSet()[col]
+  isFloat := df.FloatHeader().NameSet()[col]
+  result := &targets{}
+  if !encoder.Options.MultiClass {
+    if !isInt && !isFloat {
+      return nil, fmt.Errorf("target %s is neither a float column nor an int column", col)
+    }
+    result.values = make([]float64, df.NumRows())
+    if isFloat {
+      access := df.Floats(col)
+      for i := range result.values {
+        result.values[i] = access.Get(i)
+      }
+    } else {
+      access := df.Ints(col)
+      for i := range result.values {
+        if v := access.Get(i); v == -1 {
+          result.values[i] = math.NaN()
+        } else {
+          result.values[i] = float64(v)
+        }
+      }
+    }
+    return result, nil
+  }
+  if !isInt && !isString {
+    return nil, fmt.Errorf("target %s is neither an int column nor a string column", col)
+  }
+  keys := categoryKeys(df, col)
+  if encoder.Classes == nil {
+    seen := make(map[string]bool)
+    var ints []int
+    for i := 0; i < df.NumRows(); i++ {
+      if key, ok := keys(i); ok && !seen[key] {
+        seen[key] = true
+        encoder.Classes = append(encoder.Classes, key)
+        if isInt {
+          v, _ := strconv.Atoi(key)
+          ints = append(ints, v)
+        }
+      }
+    }
+    if isInt {
+      sort.Ints(ints)
+      for k, v := range ints {
+        encoder.Classes[k] = strconv.Itoa(v)
+      }
+    } else {
+      sort.Strings(encoder.Classes)
+    }
+  }
+  classIndex := make(map[string]int)
+  for k, class := range encoder.Classes {
+    classIndex[class] = k
+  }
+  result.classes = make([]int, df.NumRows())
+  for i := range result.classes {
+    result.classes[i] = -1
+    if key, ok := keys(i); ok {
+      if k, ok := classIndex[key]; ok {
+        result.classes[i] = k
+      }
+    }
+  }
+  return result, nil
+}
+
+// numOutputs returns the number of float columns encoding each column.
+func (encoder *TargetEncoder) numOutputs() int {
+  if encoder.Options.MultiClass {
+    return len(encoder.Classes)
+  }
+  return 1
+}
+
+// outputColumns returns the names of the float columns encoding col.
+func (encoder *TargetEncoder) outputColumns(col string) []string {
+  if !encoder.Options.MultiClass {
+    return []string{col + "_te"}
+  }
+  result := make([]string, len(encoder.Classes))
+  for k := range result {
+    result[k] = fmt.Sprintf("%s_te%d", col, k)
+  }
+  return result
+}
+
+// targetFitting holds the statistics of a categorical column, on the whole
+// dataframe and on each fold.
+type targetFitting struct {
+  encodings map[string][]float64
+  total     map[string]*targetStats
+  folds     []map[string]*targetStats
+}
+
+func (encoder *TargetEncoder) fitColumn(df *dataframe.DataFrame, col string, t *targets, folds []int) *targetFitting {
+  numOutputs := encoder.numOutputs()
+  newStats := func() *targetStats {
+    return &targetStats{sums: make([]float64, numOutputs)}
+  }
+  result := &targetFitting{total: make(map[string]*targetStats)}
+  if folds != nil {
+    result.folds = make([]map[string]*targetStats, encoder.Options.NumFolds)
+    for f := range result.folds {
+      result.folds[f] = make(map[string]*targetStats)
+    }
+  }
+  keys := categoryKeys(df, col)
+  for i := 0; i < df.NumRows(); i++ {
+    key, ok := keys(i)
+    if !ok {
+      continue
+    }
+    stats, ok := result.total[key]
+    if !ok {
+      stats = newStats()
+      result.total[key] = stats
+    }
+    t.addTo(stats, i)
+    if folds != nil {
+      foldStats, ok := result.folds[folds[i]][key]
+      if !ok {
+        foldStats = newStats()
+        result.folds[folds[i]][key] = foldStats
+      }
+      t.addTo(foldStats, i)
+    }
+  }
+  result.encodings = make(map[string][]float64, len(result.total))
+  for key, stats := range result.total {
+    result.encodings[key] = stats.smoothed(encoder.Prior, encoder.Options.Smoothing)
+  }
+  return result
+}
+
+// fit trains the encoder. If folds is not nil, the fold statistics are kept
+// in the returned map.
+func (encoder *TargetEncoder) fit(df *dataframe.DataFrame, folds []int) (map[string]*targetFitting, *targets, error) {
+  encoder.Classes = nil
+  t, err := encoder.readTargets(df)
+  if err != nil {
+    return nil, nil, err
+  }
+  columns := df.IntHeader().Except(encoder.Options.TargetColumn).NameList()
+  columns = append(columns, df.StringHeader().Except(encoder.Options.TargetColumn).NameList()...)
+  sort.Strings(columns)
+  encoder.CategoricalColumns = columns
+
+  // global mean
+  global := &targetStats{sums: make([]float64, encoder.numOutputs())}
+  for i := 0; i < df.NumRows(); i++ {
+    t.addTo(global, i)
+  }
+  encoder.Prior = global.mean()
+  if encoder.Prior == nil {
+    return nil, nil, fmt.Errorf("no valid target in column %s", encoder.Options.TargetColumn)
+  }
+  // statistics of each column
+  encoder.Encodings = make(map[string]map[string][]float64)
+  fittings := make(map[string]*targetFitting)
+  if len(columns) == 0 {
+    return fittings, t, nil
+  }
+  q := df.CreateColumnQueue(columns)
+  for i := 0; i < q.Workers; i++ {
+    go func() {
+      for col := q.Next(); len(col) > 0; col = q.Next() {
+        q.Notify(utils.ProcessedJob{Key: col, Result: encoder.fitColumn(df, col, t, folds)})
+      }
+    }()
+  }
+  for _, job := range q.Results() {
+    fitting := job.Result.(*targetFitting)
+    encoder.Encodings[job.Key] = fitting.encodings
+    fittings[job.Key] = fitting
+  }
+  return fittings, t, nil
+}
+
+// Fit implements PreprocTraining and Transform interfaces.
+// It learns the encodings of every int and string column, except the target
+// column.
+// It returns an error if the target column is missing, of the wrong type or
+// has no valid value.
+func (encoder *TargetEncoder) Fit(df *dataframe.DataFrame) error {
+  _, _, err := encoder.fit(df, nil)
+  return err
+}
+
+// encodedView allocates the output columns and removes the categorical columns
+// once encode has filled in the output columns.
+func (encoder *TargetEncoder) encodedView(df *dataframe.DataFrame, encode func(result *dataframe.DataFrame, col string)) *dataframe.DataFrame {
+  result := df.View()
+  if len(encoder.CategoricalColumns) == 0 {
+    return result
+  }
+  for _, col := range encoder.CategoricalColumns {
+    result.AllocFloats(encoder.outputColumns(col)...)
+  }
+  q := df.CreateColumnQueue(encoder.CategoricalColumns)
+  for i := 0; i < q.Workers; i++ {
+    go func() {
+      for col := q.Next(); len(col) > 0; col = q.Next() {
+        encode(result, col)
+        q.Notify(utils.ProcessedJob{Key: col})
+      }
+    }()
+  }
+  q.Wait()
+  if !encoder.Options.KeepUsedColumns {
+    result.Drop(encoder.CategoricalColumns...)
+  }
+  return result
+}
+
+// setEncodings writes the encodings of the given row into the output columns.
+func setEncodings(outputs []dataframe.FloatAccess, row int, encodings []float64) {
+  for k, access := range outputs {
+    access.Set(row, encodings[k])
+  }
+}
+
+func outputAccesses(df *dataframe.DataFrame, columns []string) []dataframe.FloatAccess {
+  result := make([]dataframe.FloatAccess, len(columns))
+  for k, col := range columns {
+    result[k] = df.Floats(col)
+  }
+  return result
+}
+
+// TransformView implements PreprocTraining and Transform interfaces.
+// The target column is not required.
+// It returns an error if a categorical column is missing or of the wrong type.
+// This function is multi-threaded.
+func (encoder *TargetEncoder) TransformView(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+  if err := checkCategoricalColumns(df, encoder.CategoricalColumns); err != nil {
+    return nil, err
+  }
+  result := encoder.encodedView(df, func(result *dataframe.DataFrame, col string) {
+    encodings := encoder.Encodings[col]
+    keys := categoryKeys(result, col)
+    outputs := outputAccesses(result, encoder.outputColumns(col))
+    for i := 0; i < result.NumRows(); i++ {
+      encoding := encoder.Prior
+      if key, ok := keys(i); ok {
+        if known, ok := encodings[key]; ok {
+          encoding = known
+        }
+      }
+      setEncodings(outputs, i, encoding)
+    }
+  })
+  return result, nil
+}
+
+// FitTransform trains the encoder on df and transforms df.
+// Unlike Fit+TransformView, the rows of df are randomly split into
+// Options.NumFolds folds and each row is encoded by the statistics of the
+// other folds, so that the target of a row does not leak into its encoding.
+// The trained encoder is the same as the one trained by Fit.
+// It returns an error if the target column is missing, of the wrong type or
+// has no valid value.
+// This function is multi-threaded.
+func (encoder *TargetEncoder) FitTransform(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+  numFolds := encoder.Options.NumFolds
+  if numFolds <= 1 {
+    if err := encoder.Fit(df); err != nil {
+      return nil, err
+    }
+    return encoder.TransformView(df)
+  }
+  // randomly assign the rows to the folds
+  folds := make([]int, df.NumRows())
+  rng := rand.New(rand.NewSource(encoder.Options.Seed))
+  for i, row := range rng.Perm(len(folds)) {
+    folds[row] = i % numFolds
+  }
+  fittings, t, err := encoder.fit(df, folds)
+  if err != nil {
+    return nil, err
+  }
+  // out-of-fold global means
+  global := &targetStats{sums: make([]float64, encoder.numOutputs())}
+  foldGlobals := make([]*targetStats, numFolds)
+  for f := range foldGlobals {
+    foldGlobals[f] = &targetStats{sums: make([]float64, len(global.sums))}
+  }
+  for i, f := range folds {
+    t.addTo(global, i)
+    t.addTo(foldGlobals[f], i)
+  }
+  priors := make([][]float64, numFolds)
+  for f := range priors {
+    if priors[f] = global.minus(foldGlobals[f]).mean(); priors[f] == nil {
+      priors[f] = encoder.Prior
+    }
+  }
+  // out-of-fold encodings
+  result := encoder.encodedView(df, func(result *dataframe.DataFrame, col string) {
+    fitting := fittings[col]
+    keys := categoryKeys(result, col)
+    outputs := outputAccesses(result, encoder.outputColumns(col))
+    for i := 0; i < result.NumRows(); i++ {
+      f := folds[i]
+      encoding := priors[f]
+      if key, ok := keys(i); ok {
+        outOfFold := fitting.total[key].minus(fitting.folds[f][key])
+        if outOfFold.count > 0 {
+          encoding = outOfFold.smoothed(priors[f], encoder.Options.Smoothing)
+        }
+      }
+      setEncodings(outputs, i, encoding)
+    }
+  })
+  return result, nil
+}
+
+// TransformedColumns implements PreprocTraining and Transform interfaces.
+func (encoder *TargetEncoder) TransformedColumns() []string {
+  return encoder.CategoricalColumns
+}
@@ -0,0 +1,98 @@
+package preprocessing
+
+import (
+  "testing"
+  "encoding/json"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+  u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func floatValues(access dataframe.FloatAccess) []float64 {
+  result := make([]float64, access.Size())
+  for i := range result {
+    result[i] = access.Get(i)
+  }
+  return result
+}
+
+func TestTargetEncoderSmoothing(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddStrings("city", "a", "a", "b", "b", "b")
+  builder.AddFloats("target", 1, 0, 1, 1, 1)
+  df := builder.ToDataFrame()
+
+  encoder := NewTargetEncoder(TargetEncoderOptions{Smoothing: 1})
+  if !u.AssertNoError(encoder.Fit(df), t) {
+    return
+  }
+  u.AssertStringSliceEquals("columns", encoder.TransformedColumns(), []string{"city"}, true, t)
+  u.AssertFloatEquals("prior", encoder.Prior[0], 0.8, t)
+
+  // serialization
+  serialized, _ := json.Marshal(encoder)
+  encoder = &TargetEncoder{}
+  json.Unmarshal(serialized, &encoder)
+
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddStrings("city", "a", "b", "c", "")
+  test := builder.ToDataFrame()
+  test.Objects("city").Set(3, nil)
+  result, err := encoder.TransformView(test)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  u.AssertIntEquals("num strings", result.StringHeader().Num(), 0, t)
+  u.AssertFloatSliceEquals("encodings", floatValues(result.Floats("city_te")), []float64{0.6, 0.95, 0.8, 0.8}, t)
+
+  // missing and float columns
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  _, err = encoder.TransformView(builder.AddStrings("town", "a").ToDataFrame())
+  u.AssertTrue("missing column", err != nil, t)
+  builder = dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  _, err = encoder.TransformView(builder.AddFloats("city", 1, 2).ToDataFrame())
+  u.AssertTrue("float column", err != nil, t)
+}
+
+func TestTargetEncoderOutOfFold(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddInts("col", 1, 1, 1, 2, 2)
+  builder.AddInts("label", 1, 2, 3, 4, 6)
+  df := builder.ToDataFrame()
+
+  // one fold per row, i.e. leave-one-out
+  encoder := NewTargetEncoder(TargetEncoderOptions{TargetColumn: "label", NumFolds: 5, KeepUsedColumns: true})
+  result, err := encoder.FitTransform(df)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  u.AssertIntEquals("kept", result.Ints("col").Get(0), 1, t)
+  u.AssertFloatSliceEquals("out-of-fold", floatValues(result.Floats("col_te")), []float64{2.5, 2, 1.5, 6, 4}, t)
+  u.AssertFloatSliceEquals("fitted", encoder.Encodings["col"]["1"], []float64{2}, t)
+  u.AssertFloatSliceEquals("fitted", encoder.Encodings["col"]["2"], []float64{5}, t)
+
+  // missing target
+  encoder = NewTargetEncoder(TargetEncoderOptions{})
+  if encoder.Fit(df) == nil {
+    t.Errorf("missing target should be reported")
+  }
+}
+
+func TestTargetEncoderMultiClass(t *testing.T) {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddInts("col", 7, 7, 8, 8)
+  builder.AddStrings("target", "x", "y", "x", "z")
+  df := builder.ToDataFrame()
+
+  encoder := NewTargetEncoder(TargetEncoderOptions{MultiClass: true})
+  if !u.AssertNoError(encoder.Fit(df), t) {
+    return
+  }
+  u.AssertStringSliceEquals("classes", encoder.Classes, []string{"x", "y", "z"}, true, t)
+  result, err := encoder.TransformView(df)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  u.AssertFloatSliceEquals("x", floatValues(result.Floats("col_te0")), []float64{0.5, 0.5, 0.5, 0.5}, t)
+  u.AssertFloatSliceEquals("y", floatValues(result.Floats("col_te1")), []float64{0.5, 0.5, 0, 0}, t)
+  u.AssertFloatSliceEquals("z", floatValues(result.Floats("col_te2")), []float64{0, 0, 0.5, 0.5}, t)
+}
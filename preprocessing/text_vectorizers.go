@@ -0,0 +1,483 @@
+package preprocessing
+
+import (
+  "fmt"
+  "hash/fnv"
+  "math"
+  "regexp"
+  "sort"
+  "strings"
+  "github.com/rom1mouret/ml-essentials/utils"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+)
+
+type TextOutput int
+
+const (
+  // One float column per term, named <col>_tok<N> or <col>_hash<N>.
+  DenseOutput TextOutput = iota
+  // One object column named <col>_vec storing a *SparseVector per row.
+  SparseOutput
+)
+
+// default token pattern: sequences of letters, digits and underscores
+const defaultTokenPattern = `[\p{L}\p{N}_]+`
+
+type TextOptions struct {
+  // Tokens are converted to lower case unless KeepCase is true.
+  KeepCase bool
+  // Regular expression matching the tokens.
+  // Default: sequences of letters, digits and underscores.
+  TokenPattern string
+  // Tokens to ignore. They are removed before building the n-grams.
+  StopWords []string
+  // Range of n-grams, e.g. MinNGram=1 and MaxNGram=2 for unigrams and
+  // bigrams. Both default to 1.
+  MinNGram int
+  MaxNGram int
+  // Terms found in fewer than MinDF documents or in more than MaxDF documents
+  // are excluded from the vocabulary. MinDF values below 1 and MaxDF values up
+  // to 1 are ratios of the number of training documents, other values are
+  // numbers of documents. Hence, MinDF=1 and MaxDF=1 both mean no limit.
+  // Zero means no limit. Ignored by HashingVectorizer.
+  MinDF float64
+  MaxDF float64
+  // Output format.
+  Output TextOutput
+  // Unless KeepUsedColumns is true, the text columns are removed from the
+  // transformed dataframe.
+  KeepUsedColumns bool
+}
+
+// SparseVector is the representation of documents when the output is
+// SparseOutput. Indices are sorted in increasing order.
+type SparseVector struct {
+  Size    int
+  Indices []int
+  Values  []float64
+}
+
+// Dense converts the sparse vector to a dense slice.
+func (vec *SparseVector) Dense() []float64 {
+  result := make([]float64, vec.Size)
+  for k, index := range vec.Indices {
+    result[index] = vec.Values[k]
+  }
+  return result
+}
+
+type tokenizer struct {
+  pattern   *regexp.Regexp
+  stopWords map[string]bool
+  opt       TextOptions
+}
+
+func newTokenizer(opt TextOptions) (*tokenizer, error) {
+  pattern := opt.TokenPattern
+  if len(pattern) == 0 {
+    pattern = defaultTokenPattern
+  }
+  re, err := regexp.Compile(pattern)
+  if err != nil {
+    return nil, err
+  }
+  if opt.MinNGram <= 0 {
+    opt.MinNGram = 1
+  }
+  if opt.MaxNGram < opt.MinNGram {
+    opt.MaxNGram = opt.MinNGram
+  }
+  return &tokenizer{pattern: re, stopWords: utils.ToStringSet(opt.StopWords), opt: opt}, nil
+}
+
+// counts returns the number of occurrences of each n-gram of the text.
+func (tok *tokenizer) counts(text string) map[string]int {
+  if !tok.opt.KeepCase {
+    text = strings.ToLower(text)
+  }
+  tokens := make([]string, 0)
+  for _, token := range tok.pattern.FindAllString(text, -1) {
+    if !tok.stopWords[token] {
+      tokens = append(tokens, token)
+    }
+  }
+  result := make(map[string]int)
+  for n := tok.opt.MinNGram; n <= tok.opt.MaxNGram; n++ {
+    for i := 0; i + n <= len(tokens); i++ {
+      result[strings.Join(tokens[i:i+n], " ")]++
+    }
+  }
+  return result
+}
+
+// textVectorizer is implemented by the vectorizers to share TransformView.
+type textVectorizer interface {
+  // number of dimensions of the vectors of the given column
+  columnSize(col string) int
+  // name of the k-th float column of a text column with DenseOutput
+  denseColumn(col string, k int) string
+  // converts term counts to a vector with sorted indices
+  vectorizer(col string) func(counts map[string]int) ([]int, []float64)
+}
+
+// sortedVector sorts the entries of the given map by index.
+func sortedVector(entries map[int]float64) ([]int, []float64) {
+  indices := make([]int, 0, len(entries))
+  for index := range entries {
+    indices = append(indices, index)
+  }
+  sort.Ints(indices)
+  values := make([]float64, len(indices))
+  for k, index := range indices {
+    values[k] = entries[index]
+  }
+  return indices, values
+}
+
+func workerTransformsText(input *dataframe.DataFrame, output *dataframe.DataFrame, tok *tokenizer, vec textVectorizer, q utils.StringQ) {
+  for col := q.Next(); len(col) > 0; col = q.Next() {
+    vectorize := vec.vectorizer(col)
+    texts := input.Objects(col)
+    if tok.opt.Output == SparseOutput {
+      vectors := output.Objects(col + "_vec")
+      size := vec.columnSize(col)
+      for i := 0; i < texts.Size(); i++ {
+        if text, ok := texts.Get(i).(string); ok {
+          indices, values := vectorize(tok.counts(text))
+          vectors.Set(i, &SparseVector{Size: size, Indices: indices, Values: values})
+        }
+      }
+    } else {
+      accesses := make([]dataframe.FloatAccess, vec.columnSize(col))
+      for k := range accesses {
+        accesses[k] = output.Floats(vec.denseColumn(col, k))
+      }
+      for i := 0; i < texts.Size(); i++ {
+        if text, ok := texts.Get(i).(string); ok {
+          indices, values := vectorize(tok.counts(text))
+          for k, index := range indices {
+            accesses[index].Set(i, values[k])
+          }
+        }
+      }
+    }
+    q.Notify(utils.ProcessedJob{Key: col})
+  }
+}
+
+// transformText vectorizes the given text columns.
+// Missing texts are converted to zero vectors with DenseOutput and remain
+// nil with SparseOutput.
+func transformText(df *dataframe.DataFrame, columns []string, opt TextOptions, vec textVectorizer) (*dataframe.DataFrame, error) {
+  result := df.View()
+  if len(columns) == 0 {
+    return result, nil
+  }
+  tok, err := newTokenizer(opt)
+  if err != nil {
+    return nil, err
+  }
+  // allocate the new columns
+  for _, col := range columns {
+    if opt.Output == SparseOutput {
+      result.AllocObjects(col + "_vec")
+    } else {
+      newCols := make([]string, vec.columnSize(col))
+      for k := range newCols {
+        newCols[k] = vec.denseColumn(col, k)
+      }
+      result.AllocFloats(newCols...)
+    }
+  }
+  // vectorize each column in parallel
+  q := df.CreateColumnQueue(columns)
+  for i := 0; i < q.Workers; i++ {
+    go workerTransformsText(df, result, tok, vec, q)
+  }
+  q.Wait()
+  if !opt.KeepUsedColumns {
+    result.Drop(columns...)
+  }
+  return result, nil
+}
+
+type vocabulary struct {
+  terms    []string
+  docFreqs []int
+  numDocs  int
+}
+
+func workerFitsVocabulary(df *dataframe.DataFrame, tok *tokenizer, q utils.StringQ) {
+  opt := tok.opt
+  for col := q.Next(); len(col) > 0; col = q.Next() {
+    texts := df.Objects(col)
+    docFreqs := make(map[string]int)
+    numDocs := 0
+    for i := 0; i < texts.Size(); i++ {
+      if text, ok := texts.Get(i).(string); ok {
+        for term := range tok.counts(text) {
+          docFreqs[term]++
+        }
+        numDocs++
+      }
+    }
+    // document frequency thresholds
+    minDF := opt.MinDF
+    if minDF < 1 {
+      minDF *= float64(numDocs)
+    }
+    maxDF := math.Inf(1)
+    if opt.MaxDF > 1 {
+      maxDF = opt.MaxDF
+    } else if opt.MaxDF > 0 {
+      maxDF = opt.MaxDF * float64(numDocs)
+    }
+    result := &vocabulary{numDocs: numDocs}
+    for term, freq := range docFreqs {
+      if float64(freq) >= minDF && float64(freq) <= maxDF {
+        result.terms = append(result.terms, term)
+      }
+    }
+    sort.Strings(result.terms)
+    result.docFreqs = make([]int, len(result.terms))
+    for k, term := range result.terms {
+      result.docFreqs[k] = docFreqs[term]
+    }
+    q.Notify(utils.ProcessedJob{Key: col, Result: result})
+  }
+}
+
+// fitVocabularies builds the vocabulary of each string column of df.
+func fitVocabularies(df *dataframe.DataFrame, opt TextOptions) ([]string, map[string]*vocabulary, error) {
+  tok, err := newTokenizer(opt)
+  if err != nil {
+    return nil, nil, err
+  }
+  columns := df.StringHeader().NameList()
+  sort.Strings(columns)
+  q := df.CreateColumnQueue(columns)
+  for i := 0; i < q.Workers; i++ {
+    go workerFitsVocabulary(df, tok, q)
+  }
+  result := make(map[string]*vocabulary)
+  for _, job := range q.Results() {
+    result[job.Key] = job.Result.(*vocabulary)
+  }
+  return columns, result, nil
+}
+
+// CountVectorizer is a json-serializable structure that converts string
+// columns into bag-of-words vectors, i.e. the number of occurrences of each
+// term of the vocabulary learnt during training.
+// Each string column has its own vocabulary. Terms are sorted alphabetically.
+type CountVectorizer struct {
+  TextColumns  []string
+  Vocabularies map[string][]string
+  Options      TextOptions
+}
+
+// NewCountVectorizer allocates a new CountVectorizer.
+func NewCountVectorizer(opt TextOptions) *CountVectorizer {
+  vec := new(CountVectorizer)
+  vec.Options = opt
+  return vec
+}
+
+// Fit implements PreprocTraining and Transform interfaces.
+// It learns the vocabulary of every string column.
+// It returns an error if the token pattern is not a valid regular expression.
+func (vec *CountVectorizer) Fit(df *dataframe.DataFrame) error {
+  columns, vocabularies, err := fitVocabularies(df, vec.Options)
+  if err != nil {
+    return err
+  }
+  vec.TextColumns = columns
+  vec.Vocabularies = make(map[string][]string)
+  for col, vocab := range vocabularies {
+    vec.Vocabularies[col] = vocab.terms
+  }
+  return nil
+}
+
+// TransformView implements PreprocTraining and Transform interfaces.
+// This function is multi-threaded.
+func (vec *CountVectorizer) TransformView(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+  return transformText(df, vec.TextColumns, vec.Options, vec)
+}
+
+// TransformedColumns implements PreprocTraining and Transform interfaces.
+func (vec *CountVectorizer) TransformedColumns() []string {
+  return vec.TextColumns
+}
+
+func (vec *CountVectorizer) columnSize(col string) int {
+  return len(vec.Vocabularies[col])
+}
+
+func (vec *CountVectorizer) denseColumn(col string, k int) string {
+  return fmt.Sprintf("%s_tok%d", col, k)
+}
+
+func (vec *CountVectorizer) vectorizer(col string) func(counts map[string]int) ([]int, []float64) {
+  termIndex := make(map[string]int)
+  for k, term := range vec.Vocabularies[col] {
+    termIndex[term] = k
+  }
+  return func(counts map[string]int) ([]int, []float64) {
+    entries := make(map[int]float64)
+    for term, count := range counts {
+      if k, ok := termIndex[term]; ok {
+        entries[k] = float64(count)
+      }
+    }
+    return sortedVector(entries)
+  }
+}
+
+type TfidfOptions struct {
+  Text TextOptions
+  // Replace term counts tf with 1 + log(tf).
+  SublinearTF bool
+  // Vectors are L2-normalized unless NoNormalization is true.
+  NoNormalization bool
+}
+
+// TfidfVectorizer is a json-serializable structure that converts string
+// columns into TF-IDF vectors, i.e. term counts weighted by the inverse
+// document frequency of the terms:
+//  idf = 1 + log((1 + number of documents) / (1 + document frequency))
+type TfidfVectorizer struct {
+  CountVectorizer
+  IDF          map[string][]float64
+  TfidfOptions TfidfOptions
+}
+
+// NewTfidfVectorizer allocates a new TfidfVectorizer.
+func NewTfidfVectorizer(opt TfidfOptions) *TfidfVectorizer {
+  vec := new(TfidfVectorizer)
+  vec.Options = opt.Text
+  vec.TfidfOptions = opt
+  return vec
+}
+
+// Fit implements PreprocTraining and Transform interfaces.
+// It learns the vocabulary and the inverse document frequencies of every
+// string column.
+// It returns an error if the token pattern is not a valid regular expression.
+func (vec *TfidfVectorizer) Fit(df *dataframe.DataFrame) error {
+  columns, vocabularies, err := fitVocabularies(df, vec.Options)
+  if err != nil {
+    return err
+  }
+  vec.TextColumns = columns
+  vec.Vocabularies = make(map[string][]string)
+  vec.IDF = make(map[string][]float64)
+  for col, vocab := range vocabularies {
+    vec.Vocabularies[col] = vocab.terms
+    idf := make([]float64, len(vocab.terms))
+    for k, freq := range vocab.docFreqs {
+      idf[k] = 1 + math.Log(float64(1 + vocab.numDocs) / float64(1 + freq))
+    }
+    vec.IDF[col] = idf
+  }
+  return nil
+}
+
+// TransformView implements PreprocTraining and Transform interfaces.
+// This function is multi-threaded.
+func (vec *TfidfVectorizer) TransformView(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+  return transformText(df, vec.TextColumns, vec.Options, vec)
+}
+
+func (vec *TfidfVectorizer) vectorizer(col string) func(counts map[string]int) ([]int, []float64) {
+  countVectorizer := vec.CountVectorizer.vectorizer(col)
+  idf := vec.IDF[col]
+  opt := vec.TfidfOptions
+  return func(counts map[string]int) ([]int, []float64) {
+    indices, values := countVectorizer(counts)
+    norm := 0.0
+    for k, index := range indices {
+      if opt.SublinearTF {
+        values[k] = 1 + math.Log(values[k])
+      }
+      values[k] *= idf[index]
+      norm += values[k] * values[k]
+    }
+    if !opt.NoNormalization && norm > 0 {
+      norm = math.Sqrt(norm)
+      for k := range values {
+        values[k] /= norm
+      }
+    }
+    return indices, values
+  }
+}
+
+type HashingOptions struct {
+  Text TextOptions
+  // Number of dimensions of the vectors. Default: 1024
+  NumFeatures int
+}
+
+// HashingVectorizer is a json-serializable structure that converts string
+// columns into vectors of term counts, wherein terms are mapped to vector
+// dimensions with a hash function instead of a vocabulary.
+// Unlike CountVectorizer, it needs no training apart from finding the string
+// columns, and the size of the vectors is bounded by NumFeatures, at the cost
+// of hashing collisions.
+type HashingVectorizer struct {
+  TextColumns []string
+  Options     HashingOptions
+}
+
+// NewHashingVectorizer allocates a new HashingVectorizer.
+func NewHashingVectorizer(opt HashingOptions) *HashingVectorizer {
+  vec := new(HashingVectorizer)
+  vec.Options = opt
+  return vec
+}
+
+// Fit implements PreprocTraining and Transform interfaces.
+func (vec *HashingVectorizer) Fit(df *dataframe.DataFrame) error {
+  vec.TextColumns = df.StringHeader().NameList()
+  sort.Strings(vec.TextColumns)
+  return nil
+}
+
+// TransformView implements PreprocTraining and Transform interfaces.
+// This function is multi-threaded.
+func (vec *HashingVectorizer) TransformView(df *dataframe.DataFrame) (*dataframe.DataFrame, error) {
+  return transformText(df, vec.TextColumns, vec.Options.Text, vec)
+}
+
+// TransformedColumns implements PreprocTraining and Transform interfaces.
+func (vec *HashingVectorizer) TransformedColumns() []string {
+  return vec.TextColumns
+}
+
+// columnSize falls back to the default number of features if NumFeatures is
+// not set, e.g. if the vectorizer is not created with NewHashingVectorizer.
+func (vec *HashingVectorizer) columnSize(col string) int {
+  if vec.Options.NumFeatures <= 0 {
+    return 1024
+  }
+  return vec.Options.NumFeatures
+}
+
+func (vec *HashingVectorizer) denseColumn(col string, k int) string {
+  return fmt.Sprintf("%s_hash%d", col, k)
+}
+
+func (vec *HashingVectorizer) vectorizer(col string) func(counts map[string]int) ([]int, []float64) {
+  hash := fnv.New32a()
+  numFeatures := uint32(vec.columnSize(col))
+  return func(counts map[string]int) ([]int, []float64) {
+    entries := make(map[int]float64)
+    for term, count := range counts {
+      hash.Reset()
+      hash.Write([]byte(term))
+      entries[int(hash.Sum32() % numFeatures)] += float64(count)
+    }
+    return sortedVector(entries)
+  }
+}
@@ -0,0 +1,113 @@
+package preprocessing
+
+import (
+  "math"
+  "testing"
+  "encoding/json"
+  "github.com/rom1mouret/ml-essentials/dataframe"
+  u "github.com/rom1mouret/ml-essentials/utils"
+)
+
+func textTestData() *dataframe.DataFrame {
+  builder := dataframe.DataBuilder{RawData: dataframe.NewRawData()}
+  builder.AddStrings("text", "The cat sat", "the dog sat", "A cat and a dog", "")
+  builder.AddFloats("height", 1, 2, 3, 4)
+  df := builder.ToDataFrame()
+  df.Objects("text").Set(3, nil)
+  return df
+}
+
+func TestCountVectorizer(t *testing.T) {
+  df := textTestData()
+  vec := NewCountVectorizer(TextOptions{StopWords: []string{"a", "and"}, MinDF: 2})
+  u.AssertNoError(vec.Fit(df), t)
+  u.AssertStringSliceEquals("vocabulary", vec.Vocabularies["text"], []string{"cat", "dog", "sat", "the"}, true, t)
+
+  // serialization
+  serialized, _ := json.Marshal(vec)
+  vec = &CountVectorizer{}
+  json.Unmarshal(serialized, &vec)
+
+  result, err := vec.TransformView(df)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  u.AssertIntEquals("num strings", result.StringHeader().Num(), 0, t)
+  u.AssertIntEquals("num floats", result.FloatHeader().Num(), 5, t)
+  u.AssertFloatEquals("cat", result.Floats("text_tok0").Get(2), 1, t)
+  u.AssertFloatEquals("the", result.Floats("text_tok3").Get(0), 1, t)
+  u.AssertFloatEquals("missing", result.Floats("text_tok3").Get(3), 0, t)
+  u.AssertIntEquals("input", df.StringHeader().Num(), 1, t)
+
+  // sparse output with bigrams
+  vec = NewCountVectorizer(TextOptions{MinNGram: 1, MaxNGram: 2, Output: SparseOutput, KeepUsedColumns: true})
+  u.AssertNoError(vec.Fit(df), t)
+  result, err = vec.TransformView(df)
+  u.AssertNoError(err, t)
+  sparse := result.Objects("text_vec").Get(1).(*SparseVector)
+  u.AssertIntEquals("size", sparse.Size, len(vec.Vocabularies["text"]), t)
+  u.AssertIntEquals("num terms", len(sparse.Indices), 5, t)
+  u.AssertTrue("missing", result.Objects("text_vec").Get(3) == nil, t)
+  u.AssertIntEquals("kept", result.StringHeader().Num(), 1, t)
+
+  // MaxDF=1 is 100% of the documents
+  vec = NewCountVectorizer(TextOptions{MaxDF: 1})
+  u.AssertNoError(vec.Fit(df), t)
+  u.AssertIntEquals("no limit", len(vec.Vocabularies["text"]), 6, t)
+  vec = NewCountVectorizer(TextOptions{MaxDF: 0.5})
+  u.AssertNoError(vec.Fit(df), t)
+  u.AssertStringSliceEquals("ratio", vec.Vocabularies["text"], []string{"a", "and"}, true, t)
+}
+
+func TestTfidfVectorizer(t *testing.T) {
+  df := textTestData()
+  vec := NewTfidfVectorizer(TfidfOptions{Text: TextOptions{Output: SparseOutput}})
+  u.AssertNoError(vec.Fit(df), t)
+  serialized, _ := json.Marshal(vec)
+  vec = &TfidfVectorizer{}
+  json.Unmarshal(serialized, &vec)
+
+  result, err := vec.TransformView(df)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  for i := 0; i < 3; i++ {
+    norm := 0.0
+    for _, v := range result.Objects("text_vec").Get(i).(*SparseVector).Values {
+      norm += v * v
+    }
+    u.AssertFloatEquals("norm", math.Sqrt(norm), 1, t)
+  }
+  // "and" is rarer than "cat" in the training data
+  u.AssertStringSliceEquals("vocabulary", vec.Vocabularies["text"][1:3], []string{"and", "cat"}, true, t)
+  dense := result.Objects("text_vec").Get(2).(*SparseVector).Dense()
+  u.AssertTrue("idf", dense[1] > dense[2], t)
+}
+
+func TestHashingVectorizer(t *testing.T) {
+  df := textTestData()
+  vec := NewHashingVectorizer(HashingOptions{NumFeatures: 8})
+  u.AssertNoError(vec.Fit(df), t)
+  result, err := vec.TransformView(df)
+  if !u.AssertNoError(err, t) {
+    return
+  }
+  u.AssertIntEquals("num floats", result.FloatHeader().Num(), 9, t)
+  total := 0.0
+  for k := 0; k < 8; k++ {
+    total += result.Floats(vec.denseColumn("text", k)).Get(2)
+  }
+  u.AssertFloatEquals("num tokens", total, 5, t)
+
+  // default number of features
+  vec = &HashingVectorizer{}
+  vec.Fit(df)
+  result, err = vec.TransformView(df)
+  u.AssertNoError(err, t)
+  u.AssertIntEquals("default size", result.FloatHeader().Num(), 1025, t)
+
+  vec = NewHashingVectorizer(HashingOptions{Text: TextOptions{TokenPattern: "("}})
+  vec.Fit(df)
+  _, err = vec.TransformView(df)
+  u.AssertTrue("invalid pattern", err != nil, t)
+}
@@ -0,0 +1,153 @@
+package utils
+
+import (
+  "math/bits"
+  "encoding/binary"
+)
+
+type HashAlgorithm int
+
+const (
+  // FNV-1a, 64-bit variant
+  // https://en.wikipedia.org/wiki/Fowler%E2%80%93Noll%E2%80%93Vo_hash_function
+  FNV HashAlgorithm = iota
+  // MurmurHash3, x86 32-bit variant
+  // https://github.com/aappleby/smhasher
+  Murmur3
+  // xxHash, 64-bit variant
+  // https://github.com/Cyan4973/xxHash
+  XXHash
+)
+
+// Hash hashes the data with the given algorithm.
+// With Murmur3, only the lower 32 bits of the seed are used and the result
+// fits in 32 bits.
+func Hash(algo HashAlgorithm, data []byte, seed uint64) uint64 {
+  switch algo {
+  case Murmur3:
+    return uint64(Murmur3Hash32(data, uint32(seed)))
+  case XXHash:
+    return XXHash64(data, seed)
+  }
+  return FNV64a(data, seed)
+}
+
+// FNV64a computes the FNV-1a hash of the data. The seed is mixed with the
+// offset basis, so that FNV64a(data, 0) is the standard FNV-1a hash.
+func FNV64a(data []byte, seed uint64) uint64 {
+  h := uint64(14695981039346656037) ^ seed
+  for _, b := range data {
+    h ^= uint64(b)
+    h *= 1099511628211
+  }
+  return h
+}
+
+// Murmur3Hash32 computes the 32-bit MurmurHash3 of the data.
+func Murmur3Hash32(data []byte, seed uint32) uint32 {
+  const (
+    c1 = 0xcc9e2d51
+    c2 = 0x1b873593
+  )
+  h := seed
+  nBlocks := len(data) / 4
+  for i := 0; i < nBlocks; i++ {
+    k := binary.LittleEndian.Uint32(data[4*i:])
+    k *= c1
+    k = bits.RotateLeft32(k, 15)
+    k *= c2
+    h ^= k
+    h = bits.RotateLeft32(h, 13)
+    h = h * 5 + 0xe6546b64
+  }
+  tail := data[4*nBlocks:]
+  var k uint32
+  switch len(tail) {
+  case 3:
+    k ^= uint32(tail[2]) << 16
+    fallthrough
+  case 2:
+    k ^= uint32(tail[1]) << 8
+    fallthrough
+  case 1:
+    k ^= uint32(tail[0])
+    k *= c1
+    k = bits.RotateLeft32(k, 15)
+    k *= c2
+    h ^= k
+  }
+  // finalization
+  h ^= uint32(len(data))
+  h ^= h >> 16
+  h *= 0x85ebca6b
+  h ^= h >> 13
+  h *= 0xc2b2ae35
+  h ^= h >> 16
+  return h
+}
+
+const (
+  xxPrime1 uint64 = 11400714785074694791
+  xxPrime2 uint64 = 14029467366897019727
+  xxPrime3 uint64 = 1609587929392839161
+  xxPrime4 uint64 = 9650029242287828579
+  xxPrime5 uint64 = 2870177450012600261
+)
+
+func xxRound(acc uint64, input uint64) uint64 {
+  acc += input * xxPrime2
+  acc = bits.RotateLeft64(acc, 31)
+  return acc * xxPrime1
+}
+
+func xxMergeRound(acc uint64, val uint64) uint64 {
+  acc ^= xxRound(0, val)
+  return acc * xxPrime1 + xxPrime4
+}
+
+// XXHash64 computes the 64-bit xxHash of the data.
+func XXHash64(data []byte, seed uint64) uint64 {
+  var h uint64
+  n := len(data)
+  if n >= 32 {
+    v1 := seed + xxPrime1 + xxPrime2
+    v2 := seed + xxPrime2
+    v3 := seed
+    v4 := seed - xxPrime1
+    for ; len(data) >= 32; data = data[32:] {
+      v1 = xxRound(v1, binary.LittleEndian.Uint64(data))
+      v2 = xxRound(v2, binary.LittleEndian.Uint64(data[8:]))
+      v3 = xxRound(v3, binary.LittleEndian.Uint64(data[16:]))
+      v4 = xxRound(v4, binary.LittleEndian.Uint64(data[24:]))
+    }
+    h = bits.RotateLeft64(v1, 1) + bits.RotateLeft64(v2, 7) +
+        bits.RotateLeft64(v3, 12) + bits.RotateLeft64(v4, 18)
+    h = xxMergeRound(h, v1)
+    h = xxMergeRound(h, v2)
+    h = xxMergeRound(h, v3)
+    h = xxMergeRound(h, v4)
+  } else {
+    h = seed + xxPrime5
+  }
+  h += uint64(n)
+  for ; len(data) >= 8; data = data[8:] {
+    h ^= xxRound(0, binary.LittleEndian.Uint64(data))
+    h = bits.RotateLeft64(h, 27) * xxPrime1 + xxPrime4
+  }
+  if len(data) >= 4 {
+    h ^= uint64(binary.LittleEndian.Uint32(data)) * xxPrime1
+    h = bits.RotateLeft64(h, 23) * xxPrime2 + xxPrime3
+    data = data[4:]
+  }
+  for _, b := range data {
+    h ^= uint64(b) * xxPrime5
+    h = bits.RotateLeft64(h, 11) * xxPrime1
+  }
+  // avalanche
+  h ^= h >> 33
+  h *= xxPrime2
+  h ^= h >> 29
+  h *= xxPrime3
+  h ^= h >> 32
+  return h
+}
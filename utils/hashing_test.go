@@ -0,0 +1,49 @@
+package utils
+
+import (
+  "testing"
+  "hash/fnv"
+)
+
+func TestFNV64a(t *testing.T) {
+  for _, s := range []string{"", "a", "hello world"} {
+    ref := fnv.New64a()
+    ref.Write([]byte(s))
+    AssertTrue(s, FNV64a([]byte(s), 0) == ref.Sum64(), t)
+  }
+  AssertTrue("seed", FNV64a([]byte("a"), 1) != FNV64a([]byte("a"), 0), t)
+}
+
+func TestMurmur3(t *testing.T) {
+  vectors := []struct {
+    s        string
+    seed     uint32
+    expected uint32
+  }{
+    {"", 0, 0},
+    {"", 1, 0x514e28b7},
+    {"", 0xffffffff, 0x81f16f39},
+    {"test", 0, 0xba6bd213},
+    {"Hello, world!", 1234, 0xfaf6cdb3},
+    {"The quick brown fox jumps over the lazy dog", 0, 0x2e4ff723},
+  }
+  for _, v := range vectors {
+    AssertTrue(v.s, Murmur3Hash32([]byte(v.s), v.seed) == v.expected, t)
+  }
+}
+
+func TestXXHash64(t *testing.T) {
+  vectors := []struct {
+    s        string
+    expected uint64
+  }{
+    {"", 0xef46db3751d8e999},
+    {"a", 0xd24ec4f1a98c6e5b},
+    {"abc", 0x44bc2cf5ad770999},
+    {"Nobody inspects the spammish repetition", 0xfbcea83c8a378bf1},
+  }
+  for _, v := range vectors {
+    AssertTrue(v.s, XXHash64([]byte(v.s), 0) == v.expected, t)
+  }
+  AssertTrue("seed", XXHash64([]byte("a"), 1) != XXHash64([]byte("a"), 0), t)
+}
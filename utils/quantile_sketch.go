@@ -0,0 +1,182 @@
+package utils
+
+import (
+  "math"
+  "sort"
+)
+
+// Centroid is a cluster of values summarized by their mean.
+type Centroid struct {
+  Mean   float64
+  Weight float64
+}
+
+// QuantileSketch is a json-serializable t-digest that estimates quantiles
+// without storing or sorting all the values.
+// Sketches built on different chunks of data can be merged together.
+// The accuracy is better near the extreme quantiles than near the median.
+// https://arxiv.org/abs/1902.04023
+type QuantileSketch struct {
+  // Higher compression means more centroids and more accurate quantiles.
+  Compression float64
+  Centroids   []Centroid
+  // values added since the last compression
+  Buffer      []float64
+  // extreme values, meaningless if the sketch is empty
+  Min         float64
+  Max         float64
+}
+
+// NewQuantileSketch allocates a new QuantileSketch.
+// If compression is zero or negative, it defaults to 200.
+func NewQuantileSketch(compression float64) *QuantileSketch {
+  if compression <= 0 {
+    compression = 200
+  }
+  return &QuantileSketch{Compression: compression}
+}
+
+// isEmpty returns true if no value was added to the sketch.
+func (sketch *QuantileSketch) isEmpty() bool {
+  return len(sketch.Centroids) == 0 && len(sketch.Buffer) == 0
+}
+
+// Add inserts a value into the sketch. NaN values are ignored.
+func (sketch *QuantileSketch) Add(value float64) {
+  if math.IsNaN(value) {
+    return
+  }
+  if sketch.isEmpty() {
+    sketch.Min = value
+    sketch.Max = value
+  }
+  sketch.Buffer = append(sketch.Buffer, value)
+  if value < sketch.Min {
+    sketch.Min = value
+  }
+  if value > sketch.Max {
+    sketch.Max = value
+  }
+  if len(sketch.Buffer) >= int(10 * sketch.Compression) {
+    sketch.compress(nil)
+  }
+}
+
+// Merge adds the content of another sketch into this sketch.
+// The other sketch is not altered.
+func (sketch *QuantileSketch) Merge(other *QuantileSketch) {
+  if other.isEmpty() {
+    return
+  }
+  if sketch.isEmpty() {
+    sketch.Min = other.Min
+    sketch.Max = other.Max
+  }
+  if other.Min < sketch.Min {
+    sketch.Min = other.Min
+  }
+  if other.Max > sketch.Max {
+    sketch.Max = other.Max
+  }
+  extra := make([]Centroid, len(other.Centroids), len(other.Centroids) + len(other.Buffer))
+  copy(extra, other.Centroids)
+  for _, v := range other.Buffer {
+    extra = append(extra, Centroid{Mean: v, Weight: 1})
+  }
+  sketch.compress(extra)
+}
+
+// Count returns the number of values added to the sketch.
+func (sketch *QuantileSketch) Count() float64 {
+  total := float64(len(sketch.Buffer))
+  for _, c := range sketch.Centroids {
+    total += c.Weight
+  }
+  return total
+}
+
+// scale maps quantiles to the k-scale that controls the size of the
+// centroids, allowing smaller centroids near the tails.
+func (sketch *QuantileSketch) scale(q float64) float64 {
+  return sketch.Compression / (2 * math.Pi) * math.Asin(2 * q - 1)
+}
+
+// compress merges the buffer and the extra centroids into the centroids.
+func (sketch *QuantileSketch) compress(extra []Centroid) {
+  all := make([]Centroid, 0, len(sketch.Centroids) + len(sketch.Buffer) + len(extra))
+  all = append(all, sketch.Centroids...)
+  all = append(all, extra...)
+  for _, v := range sketch.Buffer {
+    all = append(all, Centroid{Mean: v, Weight: 1})
+  }
+  sketch.Buffer = nil
+  if len(all) == 0 {
+    return
+  }
+  sort.Slice(all, func(i, j int) bool { return all[i].Mean < all[j].Mean })
+  total := 0.0
+  for _, c := range all {
+    total += c.Weight
+  }
+  merged := make([]Centroid, 0, len(sketch.Centroids) + 1)
+  current := all[0]
+  cumulated := 0.0  // weight before the current centroid
+  kLeft := sketch.scale(0)
+  for _, c := range all[1:] {
+    q := (cumulated + current.Weight + c.Weight) / total
+    if sketch.scale(q) - kLeft <= 1 {
+      // absorb c into the current centroid
+      weight := current.Weight + c.Weight
+      current.Mean += (c.Mean - current.Mean) * c.Weight / weight
+      current.Weight = weight
+    } else {
+      cumulated += current.Weight
+      merged = append(merged, current)
+      kLeft = sketch.scale(cumulated / total)
+      current = c
+    }
+  }
+  sketch.Centroids = append(merged, current)
+}
+
+// Quantile returns an estimate of the q-th quantile, with q between 0 and 1.
+// It returns NaN if the sketch is empty.
+func (sketch *QuantileSketch) Quantile(q float64) float64 {
+  if len(sketch.Buffer) > 0 {
+    sketch.compress(nil)
+  }
+  centroids := sketch.Centroids
+  if len(centroids) == 0 {
+    return math.NaN()
+  }
+  if q <= 0 {
+    return sketch.Min
+  }
+  if q >= 1 {
+    return sketch.Max
+  }
+  total := sketch.Count()
+  target := q * total
+  // interpolate between the centers of the centroids
+  cumulated := 0.0
+  prevCenter := 0.0
+  prevMean := sketch.Min
+  for _, c := range centroids {
+    center := cumulated + c.Weight / 2
+    if target < center {
+      if center == prevCenter {
+        return c.Mean
+      }
+      frac := (target - prevCenter) / (center - prevCenter)
+      return prevMean + frac * (c.Mean - prevMean)
+    }
+    cumulated += c.Weight
+    prevCenter = center
+    prevMean = c.Mean
+  }
+  if total == prevCenter {
+    return sketch.Max
+  }
+  frac := (target - prevCenter) / (total - prevCenter)
+  return prevMean + frac * (sketch.Max - prevMean)
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+  "fmt"
+  "math"
+  "math/rand"
+  "sort"
+  "testing"
+  "encoding/json"
+)
+
+func TestQuantileSketch(t *testing.T) {
+  rand.Seed(1)
+  values := make([]float64, 100000)
+  for i := range values {
+    values[i] = rand.ExpFloat64()
+  }
+  // sketch the data in two halves
+  sketch1 := NewQuantileSketch(0)
+  sketch2 := NewQuantileSketch(0)
+  for i, v := range values {
+    if i % 2 == 0 {
+      sketch1.Add(v)
+    } else {
+      sketch2.Add(v)
+    }
+  }
+  // serialization
+  serialized, err := json.Marshal(sketch2)
+  AssertNoError(err, t)
+  sketch2 = &QuantileSketch{}
+  AssertNoError(json.Unmarshal(serialized, sketch2), t)
+
+  sketch1.Merge(sketch2)
+  AssertFloatEquals("count", sketch1.Count(), float64(len(values)), t)
+  AssertTrue("compressed", len(sketch1.Centroids) < 1000, t)
+  sort.Float64s(values)
+  for _, q := range []float64{0.001, 0.01, 0.25, 0.5, 0.9, 0.99, 0.999} {
+    // rank of the estimate in the sorted data
+    rank := float64(sort.SearchFloat64s(values, sketch1.Quantile(q))) / float64(len(values))
+    AssertTrue(fmt.Sprintf("rank error at %v: %v", q, rank), math.Abs(rank - q) < 0.002, t)
+  }
+  AssertFloatEquals("min", sketch1.Quantile(0), values[0], t)
+  AssertFloatEquals("max", sketch1.Quantile(1), values[len(values)-1], t)
+}
+
+func TestEmptyQuantileSketch(t *testing.T) {
+  sketch := NewQuantileSketch(0)
+  AssertTrue("empty", math.IsNaN(sketch.Quantile(0.5)), t)
+  _, err := json.Marshal(sketch)
+  AssertNoError(err, t)
+  sketch.Merge(NewQuantileSketch(0))
+  sketch.Add(3)
+  AssertFloatEquals("single value", sketch.Quantile(0.5), 3, t)
+}